@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"dredger/service"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func runExport(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	ship := fs.String("ship", "", "船名")
+	start := fs.String("start", "", "开始时间，YYYY-MM-DD 或 YYYY-MM-DD hh:mm:ss（船舶时区）")
+	end := fs.String("end", "", "结束时间，只给日期时包含当天全天")
+	format := fs.String("format", service.FileFormatXLSX, "导出格式：xlsx / csv / parquet")
+	columns := fs.String("columns", "", "逗号分隔的数据库列名，为空时导出全部列")
+	encoding := fs.String("encoding", service.CSVEncodingUTF8, "CSV 编码：utf8 / gbk")
+	output := fs.String("o", "", "输出文件，默认按导入文件的命名约定写到当前目录")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *ship == "" {
+		return errors.New("必须指定 -ship")
+	}
+	startTime, endTime, err := parseRange(*ship, *start, *end)
+	if err != nil {
+		return err
+	}
+
+	opts := service.ExportOptions{
+		ShipName:  *ship,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Format:    *format,
+		Encoding:  *encoding,
+	}
+	if *columns != "" {
+		opts.Columns = strings.Split(*columns, ",")
+	}
+
+	svc, err := openService()
+	if err != nil {
+		return err
+	}
+	path := *output
+	if path == "" {
+		path = svc.ExportFileName(opts)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = svc.ExportData(ctx, w, opts)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	fmt.Printf("已导出到 %s\n", path)
+	return nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"dredger/service"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// importResult 一个文件的导入结果
+type importResult struct {
+	path     string
+	result   *service.ImportDataResult
+	err      error
+	duration time.Duration
+}
+
+func runImport(ctx context.Context, args []string) error {
+	fs := newFlagSet("import")
+	mode := fs.String("mode", service.ImportModeSkip, "时间冲突时的处理方式：skip / overwrite / fail")
+	workers := fs.Int("j", 4, "同时导入的文件数")
+	dryRun := fs.Bool("dry-run", false, "只解析和预览，不写入数据库")
+	uploader := fs.String("uploader", "dredgerctl", "记录在导入批次中的上传人")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	switch *mode {
+	case service.ImportModeSkip, service.ImportModeOverwrite, service.ImportModeFail:
+	default:
+		return fmt.Errorf("不支持的 mode: %s", *mode)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+	if *workers < 1 {
+		*workers = 1
+	}
+
+	files, err := collectImportFiles(fs.Args())
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return errors.New("没有找到可导入的文件")
+	}
+
+	svc, err := openService()
+	if err != nil {
+		return err
+	}
+
+	// 同一艘船的文件依次导入，避免并发事务在同一船舶的数据和 data_date 上相互等锁
+	var (
+		shipMu   sync.Mutex
+		shipLock = make(map[string]*sync.Mutex)
+	)
+	lockShip := func(shipName string) *sync.Mutex {
+		shipMu.Lock()
+		defer shipMu.Unlock()
+		if shipLock[shipName] == nil {
+			shipLock[shipName] = &sync.Mutex{}
+		}
+		return shipLock[shipName]
+	}
+
+	paths := make(chan string)
+	results := make(chan importResult)
+	var wg sync.WaitGroup
+	for range *workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				start := time.Now()
+				result, err := importFile(ctx, svc, path, service.ImportOptions{
+					Mode:     *mode,
+					DryRun:   *dryRun,
+					Uploader: *uploader,
+				}, lockShip)
+				results <- importResult{path: path, result: result, err: err, duration: time.Since(start)}
+			}
+		}()
+	}
+	go func() {
+		defer close(paths)
+		for _, path := range files {
+			select {
+			case paths <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var (
+		failed        int
+		total, parsed int
+	)
+	for r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Printf("[失败] %s: %v\n", r.path, r.err)
+			continue
+		}
+		total += r.result.ImportedRows
+		parsed += r.result.ParsedRows
+		fmt.Printf("[完成] %s: 解析 %d 行，写入 %d 行，覆盖 %d 行，未变 %d 行，跳过 %d 行，耗时 %.1fs\n",
+			r.path, r.result.ParsedRows, r.result.ImportedRows, r.result.Buckets.Overwritten,
+			r.result.Buckets.Unchanged, r.result.SkippedRows, r.duration.Seconds())
+		if preview := r.result.Preview; preview != nil && preview.ImportedBatch != nil {
+			fmt.Printf("       该文件已于 %s 导入（批次 %d），正式导入前须先回滚该批次\n",
+				time.UnixMilli(preview.ImportedBatch.CreatedAt).Format(time.DateTime), preview.ImportedBatch.ID)
+		}
+		if r.result.RejectPath != "" {
+			fmt.Printf("       被拒绝的行见 %s\n", r.result.RejectPath)
+		}
+	}
+
+	fmt.Printf("\n共 %d 个文件，失败 %d 个，解析 %d 行，写入 %d 行\n", len(files), failed, parsed, total)
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个文件导入失败", failed)
+	}
+	return nil
+}
+
+// importFile 按文件名约定解析船名和起止日期后导入
+func importFile(ctx context.Context, svc *service.Service, path string, opts service.ImportOptions, lockShip func(string) *sync.Mutex) (*service.ImportDataResult, error) {
+	name := filepath.Base(path)
+	shipName, startDate, endDate, err := service.ParseImportFileName(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mu := lockShip(shipName)
+	mu.Lock()
+	defer mu.Unlock()
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	opts.FileName = name
+	opts.ShipName = shipName
+	opts.StartDate = startDate
+	opts.EndDate = endDate
+	return svc.ImportData(ctx, f, opts, nil)
+}
+
+// collectImportFiles 展开参数中的目录（不递归），返回按路径排序的可导入文件
+func collectImportFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && service.IsImportFile(e.Name()) {
+				files = append(files, filepath.Join(arg, e.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
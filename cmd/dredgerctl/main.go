@@ -0,0 +1,225 @@
+// dredgerctl 是疏浚数据的命令行工具，与服务共用 service 包的导入、导出和迁移逻辑，
+// 数据库连接信息读取 dredger.yaml。
+//
+// 用法：
+//
+//	dredgerctl [-c dredger.yaml] <命令> [参数]
+//
+// 命令：
+//
+//	import     导入文件或目录中的 XLSX/CSV/Parquet 文件，多个文件并行导入
+//	export     导出某船一段时间内的施工数据
+//	migrate    执行数据库迁移
+//	coverage   重建 data_date 覆盖统计
+//	recompute  重新计算一段时间内保存的派生数据；目前只有 data_date，等同于限定时间范围的 coverage rebuild
+package main
+
+import (
+	"context"
+	"dredger/pkg/conf"
+	"dredger/pkg/database"
+	"dredger/pkg/logger"
+	"dredger/service"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	gormLogger "gorm.io/gorm/logger"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+// commands 在 init 中赋值，子命令的用法说明也从这里读取
+var commands []command
+
+func init() {
+	commands = []command{
+		{"import", "import [-mode skip|overwrite|fail] [-j 并发数] [-dry-run] <文件或目录>...", runImport},
+		{"export", "export -ship 船名 -start 开始时间 -end 结束时间 [-format xlsx|csv|parquet] [-columns 列名,...] [-encoding utf8|gbk] [-o 输出文件]", runExport},
+		{"migrate", "migrate", runMigrate},
+		{"coverage", "coverage rebuild [-ship 船名]", runCoverage},
+		{"recompute", "recompute [-ship 船名] -start 开始时间 -end 结束时间（目前等同于限定时间范围的 coverage rebuild）", runRecompute},
+	}
+}
+
+// errUsage 参数有误，已输出用法
+var errUsage = errors.New("参数有误")
+
+func main() {
+	configPath := flag.String("c", "./dredger.yaml", "配置文件路径")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == flag.Arg(0) {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "未知的命令: %s\n\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	conf.InitConf(*configPath)
+	logger.InitLogger("dredgerctl")
+
+	// Ctrl+C 时取消正在执行的导入，已开始的事务回滚
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, flag.Args()[1:]); err != nil {
+		if !errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "%s 失败: %v\n", cmd.name, err)
+		}
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "用法: dredgerctl [-c dredger.yaml] <命令> [参数]\n\n命令:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", c.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\n全局参数:\n")
+	flag.PrintDefaults()
+}
+
+// newFlagSet 创建子命令的参数集，解析失败时输出该命令的用法
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		for _, c := range commands {
+			if c.name == name {
+				fmt.Fprintf(fs.Output(), "用法: dredgerctl %s\n", c.usage)
+			}
+		}
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// openService 连接数据库并创建 Service；命令行不接管服务端的导入任务，不会中断服务端正在执行的任务
+func openService() (*service.Service, error) {
+	db, err := database.Open(gormLogger.Silent)
+	if err != nil {
+		return nil, fmt.Errorf("连接数据库失败: %v", err)
+	}
+	return service.NewService(db), nil
+}
+
+// parseTime 按船舶时区解析命令行中的时间，接受 "2006-01-02" 和 "2006-01-02 15:04:05"
+func parseTime(shipName, value string) (int64, error) {
+	loc := service.ShipLocation(shipName)
+	for _, layout := range []string{time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), loc); err == nil {
+			return t.UnixMilli(), nil
+		}
+	}
+	return 0, fmt.Errorf("时间格式有误: %q，应为 YYYY-MM-DD 或 YYYY-MM-DD hh:mm:ss", value)
+}
+
+// parseRange 解析 -start 和 -end；只给日期时 -end 取当天结束
+func parseRange(shipName, start, end string) (int64, int64, error) {
+	if start == "" || end == "" {
+		return 0, 0, errors.New("必须指定 -start 和 -end")
+	}
+	startTime, err := parseTime(shipName, start)
+	if err != nil {
+		return 0, 0, err
+	}
+	endTime, err := parseTime(shipName, end)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err = time.Parse(time.DateOnly, strings.TrimSpace(end)); err == nil {
+		endTime = time.UnixMilli(endTime).In(service.ShipLocation(shipName)).AddDate(0, 0, 1).UnixMilli() - 1
+	}
+	if startTime > endTime {
+		return 0, 0, errors.New("开始时间晚于结束时间")
+	}
+	return startTime, endTime, nil
+}
+
+func runMigrate(_ context.Context, args []string) error {
+	if err := parseFlags(newFlagSet("migrate"), args); err != nil {
+		return err
+	}
+	db, err := database.Open(gormLogger.Warn)
+	if err != nil {
+		return fmt.Errorf("连接数据库失败: %v", err)
+	}
+	if err = database.Migrate(db, service.Migrations()...); err != nil {
+		return err
+	}
+	fmt.Println("数据库迁移完成")
+	return nil
+}
+
+func runCoverage(_ context.Context, args []string) error {
+	fs := newFlagSet("coverage")
+	ship := fs.String("ship", "", "船名，为空时重建所有船舶")
+	if len(args) == 0 || args[0] != "rebuild" {
+		fs.Usage()
+		return errUsage
+	}
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return err
+	}
+
+	svc, err := openService()
+	if err != nil {
+		return err
+	}
+	result, err := svc.RebuildDataDates(*ship)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已重建 data_date：%d 艘船，%d 天，%d 行数据\n", result.Ships, result.Days, result.Records)
+	return nil
+}
+
+func runRecompute(ctx context.Context, args []string) error {
+	fs := newFlagSet("recompute")
+	ship := fs.String("ship", "", "船名，为空时处理所有船舶")
+	start := fs.String("start", "", "开始时间")
+	end := fs.String("end", "", "结束时间")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	startTime, endTime, err := parseRange(*ship, *start, *end)
+	if err != nil {
+		return err
+	}
+
+	svc, err := openService()
+	if err != nil {
+		return err
+	}
+	result, err := svc.RecomputeDerivedData(ctx, *ship, startTime, endTime)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已重新计算派生数据（data_date）：%d 艘船，%d 天，%d 行数据\n", result.Ships, result.Days, result.Records)
+	return nil
+}
@@ -30,6 +30,9 @@ func newDataDate(db *gorm.DB, opts ...gen.DOOption) dataDate {
 	_dataDate.ID = field.NewInt32(tableName, "id")
 	_dataDate.ShipName = field.NewString(tableName, "ship_name")
 	_dataDate.Date = field.NewInt64(tableName, "date")
+	_dataDate.RecordCount = field.NewInt64(tableName, "record_count")
+	_dataDate.FirstTime = field.NewInt64(tableName, "first_time")
+	_dataDate.LastTime = field.NewInt64(tableName, "last_time")
 
 	_dataDate.fillFieldMap()
 
@@ -39,10 +42,13 @@ func newDataDate(db *gorm.DB, opts ...gen.DOOption) dataDate {
 type dataDate struct {
 	dataDateDo
 
-	ALL      field.Asterisk
-	ID       field.Int32
-	ShipName field.String
-	Date     field.Int64
+	ALL         field.Asterisk
+	ID          field.Int32
+	ShipName    field.String
+	Date        field.Int64
+	RecordCount field.Int64
+	FirstTime   field.Int64
+	LastTime    field.Int64
 
 	fieldMap map[string]field.Expr
 }
@@ -62,6 +68,9 @@ func (d *dataDate) updateTableName(table string) *dataDate {
 	d.ID = field.NewInt32(table, "id")
 	d.ShipName = field.NewString(table, "ship_name")
 	d.Date = field.NewInt64(table, "date")
+	d.RecordCount = field.NewInt64(table, "record_count")
+	d.FirstTime = field.NewInt64(table, "first_time")
+	d.LastTime = field.NewInt64(table, "last_time")
 
 	d.fillFieldMap()
 
@@ -78,10 +87,13 @@ func (d *dataDate) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (d *dataDate) fillFieldMap() {
-	d.fieldMap = make(map[string]field.Expr, 3)
+	d.fieldMap = make(map[string]field.Expr, 6)
 	d.fieldMap["id"] = d.ID
 	d.fieldMap["ship_name"] = d.ShipName
 	d.fieldMap["date"] = d.Date
+	d.fieldMap["record_count"] = d.RecordCount
+	d.fieldMap["first_time"] = d.FirstTime
+	d.fieldMap["last_time"] = d.LastTime
 }
 
 func (d dataDate) clone(db *gorm.DB) dataDate {
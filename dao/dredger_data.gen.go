@@ -144,6 +144,7 @@ func newDredgerDatum(db *gorm.DB, opts ...gen.DOOption) dredgerDatum {
 	_dredgerDatum.CurrentWorklineEndYDpm = field.NewFloat64(tableName, "current_workline_end_y_dpm")
 	_dredgerDatum.PreviousCutterDepthDpm = field.NewFloat64(tableName, "previous_cutter_depth_dpm")
 	_dredgerDatum.ShipDeviationAngle = field.NewFloat64(tableName, "ship_deviation_angle")
+	_dredgerDatum.BatchID = field.NewInt64(tableName, "batch_id")
 
 	_dredgerDatum.fillFieldMap()
 
@@ -272,6 +273,7 @@ type dredgerDatum struct {
 	CurrentWorklineEndYDpm            field.Float64 // 当前工作线终点y(自DPM)
 	PreviousCutterDepthDpm            field.Float64 // 绞刀头点上一次的深度(自DPM)
 	ShipDeviationAngle                field.Float64 // 船体偏移工作线角度
+	BatchID                           field.Int64   // 导入批次ID
 
 	fieldMap map[string]field.Expr
 }
@@ -405,6 +407,7 @@ func (d *dredgerDatum) updateTableName(table string) *dredgerDatum {
 	d.CurrentWorklineEndYDpm = field.NewFloat64(table, "current_workline_end_y_dpm")
 	d.PreviousCutterDepthDpm = field.NewFloat64(table, "previous_cutter_depth_dpm")
 	d.ShipDeviationAngle = field.NewFloat64(table, "ship_deviation_angle")
+	d.BatchID = field.NewInt64(table, "batch_id")
 
 	d.fillFieldMap()
 
@@ -421,7 +424,7 @@ func (d *dredgerDatum) GetFieldByName(fieldName string) (field.OrderExpr, bool)
 }
 
 func (d *dredgerDatum) fillFieldMap() {
-	d.fieldMap = make(map[string]field.Expr, 117)
+	d.fieldMap = make(map[string]field.Expr, 118)
 	d.fieldMap["id"] = d.ID
 	d.fieldMap["ship_name"] = d.ShipName
 	d.fieldMap["record_time"] = d.RecordTime
@@ -539,6 +542,7 @@ func (d *dredgerDatum) fillFieldMap() {
 	d.fieldMap["current_workline_end_y_dpm"] = d.CurrentWorklineEndYDpm
 	d.fieldMap["previous_cutter_depth_dpm"] = d.PreviousCutterDepthDpm
 	d.fieldMap["ship_deviation_angle"] = d.ShipDeviationAngle
+	d.fieldMap["batch_id"] = d.BatchID
 }
 
 func (d dredgerDatum) clone(db *gorm.DB) dredgerDatum {
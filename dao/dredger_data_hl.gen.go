@@ -160,6 +160,7 @@ func newDredgerDataHl(db *gorm.DB, opts ...gen.DOOption) dredgerDataHl {
 	_dredgerDataHl.FuelTank12Level = field.NewFloat64(tableName, "fuel_tank_12_level")
 	_dredgerDataHl.FreshwaterTank26Level = field.NewFloat64(tableName, "freshwater_tank_26_level")
 	_dredgerDataHl.FuelTank4ALevel = field.NewFloat64(tableName, "fuel_tank_4a_level")
+	_dredgerDataHl.BatchID = field.NewInt64(tableName, "batch_id")
 
 	_dredgerDataHl.fillFieldMap()
 
@@ -304,6 +305,7 @@ type dredgerDataHl struct {
 	FuelTank12Level                     field.Float64 // [SBCR]燃油舱12液位(m)
 	FreshwaterTank26Level               field.Float64 // [SBCR]淡水舱26液位(m)
 	FuelTank4ALevel                     field.Float64 // [SBCR]燃油舱4A液位显示状态(m)
+	BatchID                             field.Int64   // 导入批次ID
 
 	fieldMap map[string]field.Expr
 }
@@ -453,6 +455,7 @@ func (d *dredgerDataHl) updateTableName(table string) *dredgerDataHl {
 	d.FuelTank12Level = field.NewFloat64(table, "fuel_tank_12_level")
 	d.FreshwaterTank26Level = field.NewFloat64(table, "freshwater_tank_26_level")
 	d.FuelTank4ALevel = field.NewFloat64(table, "fuel_tank_4a_level")
+	d.BatchID = field.NewInt64(table, "batch_id")
 
 	d.fillFieldMap()
 
@@ -469,7 +472,7 @@ func (d *dredgerDataHl) GetFieldByName(fieldName string) (field.OrderExpr, bool)
 }
 
 func (d *dredgerDataHl) fillFieldMap() {
-	d.fieldMap = make(map[string]field.Expr, 133)
+	d.fieldMap = make(map[string]field.Expr, 134)
 	d.fieldMap["id"] = d.ID
 	d.fieldMap["ship_name"] = d.ShipName
 	d.fieldMap["record_time"] = d.RecordTime
@@ -603,6 +606,7 @@ func (d *dredgerDataHl) fillFieldMap() {
 	d.fieldMap["fuel_tank_12_level"] = d.FuelTank12Level
 	d.fieldMap["freshwater_tank_26_level"] = d.FreshwaterTank26Level
 	d.fieldMap["fuel_tank_4a_level"] = d.FuelTank4ALevel
+	d.fieldMap["batch_id"] = d.BatchID
 }
 
 func (d dredgerDataHl) clone(db *gorm.DB) dredgerDataHl {
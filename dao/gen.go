@@ -17,20 +17,28 @@ import (
 
 var (
 	Q                  = new(Query)
+	CrewAssignment     *crewAssignment
 	DataDate           *dataDate
 	DbMigration        *dbMigration
 	DredgerDataHl      *dredgerDataHl
 	DredgerDatum       *dredgerDatum
+	ImportBatch        *importBatch
+	ImportJob          *importJob
+	Ship               *ship
 	SoilRegion         *soilRegion
 	TheoryOptimalParam *theoryOptimalParam
 )
 
 func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
+	CrewAssignment = &Q.CrewAssignment
 	DataDate = &Q.DataDate
 	DbMigration = &Q.DbMigration
 	DredgerDataHl = &Q.DredgerDataHl
 	DredgerDatum = &Q.DredgerDatum
+	ImportBatch = &Q.ImportBatch
+	ImportJob = &Q.ImportJob
+	Ship = &Q.Ship
 	SoilRegion = &Q.SoilRegion
 	TheoryOptimalParam = &Q.TheoryOptimalParam
 }
@@ -38,10 +46,14 @@ func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
 		db:                 db,
+		CrewAssignment:     newCrewAssignment(db, opts...),
 		DataDate:           newDataDate(db, opts...),
 		DbMigration:        newDbMigration(db, opts...),
 		DredgerDataHl:      newDredgerDataHl(db, opts...),
 		DredgerDatum:       newDredgerDatum(db, opts...),
+		ImportBatch:        newImportBatch(db, opts...),
+		ImportJob:          newImportJob(db, opts...),
+		Ship:               newShip(db, opts...),
 		SoilRegion:         newSoilRegion(db, opts...),
 		TheoryOptimalParam: newTheoryOptimalParam(db, opts...),
 	}
@@ -50,10 +62,14 @@ func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 type Query struct {
 	db *gorm.DB
 
+	CrewAssignment     crewAssignment
 	DataDate           dataDate
 	DbMigration        dbMigration
 	DredgerDataHl      dredgerDataHl
 	DredgerDatum       dredgerDatum
+	ImportBatch        importBatch
+	ImportJob          importJob
+	Ship               ship
 	SoilRegion         soilRegion
 	TheoryOptimalParam theoryOptimalParam
 }
@@ -63,10 +79,14 @@ func (q *Query) Available() bool { return q.db != nil }
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
 		db:                 db,
+		CrewAssignment:     q.CrewAssignment.clone(db),
 		DataDate:           q.DataDate.clone(db),
 		DbMigration:        q.DbMigration.clone(db),
 		DredgerDataHl:      q.DredgerDataHl.clone(db),
 		DredgerDatum:       q.DredgerDatum.clone(db),
+		ImportBatch:        q.ImportBatch.clone(db),
+		ImportJob:          q.ImportJob.clone(db),
+		Ship:               q.Ship.clone(db),
 		SoilRegion:         q.SoilRegion.clone(db),
 		TheoryOptimalParam: q.TheoryOptimalParam.clone(db),
 	}
@@ -83,30 +103,42 @@ func (q *Query) WriteDB() *Query {
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
 		db:                 db,
+		CrewAssignment:     q.CrewAssignment.replaceDB(db),
 		DataDate:           q.DataDate.replaceDB(db),
 		DbMigration:        q.DbMigration.replaceDB(db),
 		DredgerDataHl:      q.DredgerDataHl.replaceDB(db),
 		DredgerDatum:       q.DredgerDatum.replaceDB(db),
+		ImportBatch:        q.ImportBatch.replaceDB(db),
+		ImportJob:          q.ImportJob.replaceDB(db),
+		Ship:               q.Ship.replaceDB(db),
 		SoilRegion:         q.SoilRegion.replaceDB(db),
 		TheoryOptimalParam: q.TheoryOptimalParam.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
+	CrewAssignment     ICrewAssignmentDo
 	DataDate           IDataDateDo
 	DbMigration        IDbMigrationDo
 	DredgerDataHl      IDredgerDataHlDo
 	DredgerDatum       IDredgerDatumDo
+	ImportBatch        IImportBatchDo
+	ImportJob          IImportJobDo
+	Ship               IShipDo
 	SoilRegion         ISoilRegionDo
 	TheoryOptimalParam ITheoryOptimalParamDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
+		CrewAssignment:     q.CrewAssignment.WithContext(ctx),
 		DataDate:           q.DataDate.WithContext(ctx),
 		DbMigration:        q.DbMigration.WithContext(ctx),
 		DredgerDataHl:      q.DredgerDataHl.WithContext(ctx),
 		DredgerDatum:       q.DredgerDatum.WithContext(ctx),
+		ImportBatch:        q.ImportBatch.WithContext(ctx),
+		ImportJob:          q.ImportJob.WithContext(ctx),
+		Ship:               q.Ship.WithContext(ctx),
 		SoilRegion:         q.SoilRegion.WithContext(ctx),
 		TheoryOptimalParam: q.TheoryOptimalParam.WithContext(ctx),
 	}
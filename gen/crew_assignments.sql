@@ -0,0 +1,12 @@
+CREATE TABLE `crew_assignments`
+(
+    `id`         bigint       NOT NULL AUTO_INCREMENT,
+    `created_at` datetime(3)  DEFAULT NULL,
+    `updated_at` datetime(3)  DEFAULT NULL,
+    `ship_name`  varchar(191) NOT NULL,
+    `date`       varchar(10)  NOT NULL COMMENT '班次开始的日期(船舶时区)',
+    `shift_name` varchar(64)  NOT NULL COMMENT '班次名称',
+    `crew`       varchar(64)  NOT NULL COMMENT '班组',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY   `uk_crew_assignment` (`ship_name`,`date`,`shift_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
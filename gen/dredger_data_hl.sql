@@ -133,6 +133,8 @@ CREATE TABLE `dredger_data_hl`
     `fuel_tank_12_level` double DEFAULT NULL COMMENT '[SBCR]燃油舱12液位(m)',
     `freshwater_tank_26_level` double DEFAULT NULL COMMENT '[SBCR]淡水舱26液位(m)',
     `fuel_tank_4a_level` double DEFAULT NULL COMMENT '[SBCR]燃油舱4A液位显示状态(m)',
+    `batch_id` bigint DEFAULT NULL COMMENT '导入批次ID',
     PRIMARY KEY (`id`),
-    KEY `idx_ship_time` (`ship_name`, `record_time`) COMMENT '船名和时间索引，用于快速查询'
+    UNIQUE KEY `uk_ship_time` (`ship_name`, `record_time`) COMMENT '同一船舶同一时间只有一条记录',
+    KEY `idx_batch` (`batch_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='华安龙绞吸式挖泥船施工数据表';
\ No newline at end of file
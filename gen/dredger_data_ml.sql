@@ -117,6 +117,8 @@ CREATE TABLE `dredger_data`
     `current_workline_end_y_dpm` double DEFAULT NULL COMMENT '当前工作线终点y(自DPM)',
     `previous_cutter_depth_dpm` double DEFAULT NULL COMMENT '绞刀头点上一次的深度(自DPM)',
     `ship_deviation_angle` double DEFAULT NULL COMMENT '船体偏移工作线角度',
+    `batch_id`             bigint  DEFAULT NULL COMMENT '导入批次ID',
     PRIMARY KEY (`id`),
-    KEY                    `idx_ship_time` (`ship_name`,`record_time`) COMMENT '船名和时间索引，用于快速查询'
+    UNIQUE KEY             `uk_ship_time` (`ship_name`,`record_time`) COMMENT '同一船舶同一时间只有一条记录',
+    KEY                    `idx_batch` (`batch_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='敏龙绞吸式挖泥船施工数据表'
\ No newline at end of file
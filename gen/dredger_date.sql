@@ -29,8 +29,11 @@ CREATE TABLE `data_date` (
   `id` int NOT NULL AUTO_INCREMENT,
   `ship_name` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL,
   `date` bigint DEFAULT NULL,
+  `record_count` bigint NOT NULL DEFAULT '0' COMMENT '当天数据行数',
+  `first_time` bigint DEFAULT NULL COMMENT '当天第一条数据的时间',
+  `last_time` bigint DEFAULT NULL COMMENT '当天最后一条数据的时间',
   PRIMARY KEY (`id`),
-  UNIQUE KEY `idx_ship_date` (`ship_name`,`date`) USING BTREE
+  UNIQUE KEY `uk_ship_date` (`ship_name`,`date`) USING BTREE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
 
 
@@ -14,6 +14,8 @@ func main() {
 	g := gen.NewGenerator(gen.Config{
 		OutPath: "./dao",
 		Mode:    gen.WithoutContext | gen.WithDefaultQuery | gen.WithQueryInterface, // generate mode
+		// 保留索引标签，重新生成的模型与 AutoMigrate 建出的索引一致
+		FieldWithIndexTag: true,
 	})
 
 	gormdb, _ := gorm.Open(mysql.Open("root:5023152@(127.0.0.1:3306)/dredger?charset=utf8mb4&parseTime=True&loc=Local"))
@@ -0,0 +1,29 @@
+CREATE TABLE `import_batches`
+(
+    `id`               bigint       NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+    `created_at`       datetime(3)  DEFAULT NULL COMMENT '导入时间',
+    `updated_at`       datetime(3)  DEFAULT NULL COMMENT '更新时间',
+    `job_id`           bigint       DEFAULT NULL COMMENT '导入任务ID',
+    `file_name`        varchar(255) NOT NULL COMMENT '上传文件名',
+    `file_hash`        char(64)     NOT NULL COMMENT '文件SHA-256',
+    `ship_name`        varchar(191) NOT NULL COMMENT '船名',
+    `uploader`         varchar(191) DEFAULT NULL COMMENT '上传人',
+    `mode`             varchar(16)  DEFAULT NULL COMMENT '导入模式',
+    `start_date`       bigint       DEFAULT NULL COMMENT '文件名中的起始日期',
+    `end_date`         bigint       DEFAULT NULL COMMENT '文件名中的结束日期',
+    `start_time`       bigint       DEFAULT NULL COMMENT '最早记录时间',
+    `end_time`         bigint       DEFAULT NULL COMMENT '最晚记录时间',
+    `rows_parsed`      bigint       DEFAULT NULL COMMENT '解析行数',
+    `rows_inserted`    bigint       DEFAULT NULL COMMENT '新增行数',
+    `rows_overwritten` bigint       DEFAULT NULL COMMENT '覆盖行数',
+    `rows_unchanged`   bigint       DEFAULT NULL COMMENT '已存在而跳过的行数',
+    `rows_skipped`     bigint       DEFAULT NULL COMMENT '格式有误的行数',
+    `status`           varchar(32)  NOT NULL COMMENT '批次状态',
+    `rows_deleted`     bigint       DEFAULT NULL COMMENT '回滚时删除的行数',
+    `rolled_back_at`   datetime(3)  DEFAULT NULL COMMENT '回滚时间',
+    `active_hash`      char(64)     DEFAULT NULL COMMENT '未回滚批次的文件SHA-256，回滚后置空',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY         `uk_import_batches_active_hash` (`active_hash`) COMMENT '同一文件只能有一个未回滚的批次',
+    KEY                `idx_import_batches_hash` (`file_hash`),
+    KEY                `idx_import_batches_ship` (`ship_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='数据导入批次表，记录每次成功导入的来源文件，施工数据通过 batch_id 关联';
@@ -0,0 +1,21 @@
+CREATE TABLE `import_jobs`
+(
+    `id`            bigint       NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+    `created_at`    datetime(3)  DEFAULT NULL COMMENT '创建时间',
+    `updated_at`    datetime(3)  DEFAULT NULL COMMENT '更新时间',
+    `file_name`     varchar(255) NOT NULL COMMENT '上传文件名',
+    `ship_name`     varchar(191) NOT NULL COMMENT '船名',
+    `cover`         tinyint(1)   DEFAULT NULL COMMENT '是否覆盖已有数据',
+    `mode`          varchar(16)  DEFAULT NULL COMMENT '导入模式',
+    `status`        varchar(32)  NOT NULL COMMENT '任务状态',
+    `phase`         varchar(32)  DEFAULT NULL COMMENT '当前阶段',
+    `rows_parsed`   bigint       DEFAULT NULL COMMENT '已解析行数',
+    `rows_inserted` bigint       DEFAULT NULL COMMENT '已写入行数',
+    `rows_skipped`  bigint       DEFAULT NULL COMMENT '已跳过行数',
+    `error`         text COMMENT '失败原因',
+    `result`        mediumtext COMMENT '导入结果(JSON)',
+    `started_at`    datetime(3)  DEFAULT NULL COMMENT '开始执行时间',
+    `finished_at`   datetime(3)  DEFAULT NULL COMMENT '结束时间',
+    PRIMARY KEY (`id`),
+    KEY             `idx_import_jobs_ship` (`ship_name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='数据导入任务表';
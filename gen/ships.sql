@@ -0,0 +1,21 @@
+CREATE TABLE `ships`
+(
+    `id`               bigint       NOT NULL AUTO_INCREMENT,
+    `created_at`       datetime(3)  DEFAULT NULL,
+    `updated_at`       datetime(3)  DEFAULT NULL,
+    `name`             varchar(191) NOT NULL COMMENT '船名',
+    `data_schema`      varchar(32)  NOT NULL COMMENT '施工数据结构(hl/ml)',
+    `timezone`         varchar(64)  DEFAULT NULL COMMENT '时区',
+    `hydraulics`       text COMMENT '吸入管路水力参数(JSON)',
+    `shift_schedule`   text COMMENT '班次划分(JSON)',
+    `soil_model`       varchar(32)  DEFAULT NULL COMMENT '土质模型(none/regions)',
+    `sensor_points`    text COMMENT '实时传感器点位映射(JSON)',
+    `field_mapping`    text COMMENT '统一视图字段映射(JSON)',
+    `energy_model`     text COMMENT '能耗模型(JSON)',
+    `duration_rule`    text COMMENT '施工时长计算规则(JSON)',
+    `state_rule`       text COMMENT '运行状态阈值(JSON)',
+    `crew_rotation`    text COMMENT '班组轮换(JSON)',
+    `soil_coordinates` text COMMENT '土质区域坐标字段(JSON)',
+    PRIMARY KEY (`id`),
+    UNIQUE KEY         `uk_ships_name` (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
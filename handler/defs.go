@@ -7,6 +7,7 @@ type errcode int
 const (
 	errBadRequest errcode = 10001 + iota
 	errInternalServer
+	errConflict
 )
 
 func (e errcode) String() string {
@@ -15,6 +16,8 @@ func (e errcode) String() string {
 		return "请求内容有误"
 	case errInternalServer:
 		return "服务处理错误"
+	case errConflict:
+		return "数据冲突"
 	default:
 		return "位置错误"
 	}
@@ -24,6 +27,31 @@ type importDataRequest struct {
 	File     *multipart.FileHeader `form:"file" binding:"required"`
 	ShipName string                `form:"shipName" binding:"required"`
 	Cover    bool                  `form:"cover"`
+	Mode     string                `form:"mode" binding:"omitempty,oneof=skip overwrite fail"` // 时间冲突时的处理方式，为空时由 cover 决定
+	DryRun   bool                  `form:"dryRun"`                                             // 只预览解析结果，不写库
+	Uploader string                `form:"uploader"`                                           // 上传人，为空时记录客户端 IP
+}
+
+type listImportBatchesRequest struct {
+	ShipName string `form:"shipName"`
+	Limit    int    `form:"limit"`
+}
+
+type importBatchUri struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+type listImportJobsRequest struct {
+	ShipName string `form:"shipName"`
+	Limit    int    `form:"limit"`
+}
+
+type importJobUri struct {
+	ID int64 `uri:"id" binding:"required"`
+}
+
+type shipUri struct {
+	Name string `uri:"name" binding:"required"`
 }
 
 type commonRequest struct {
@@ -32,12 +60,61 @@ type commonRequest struct {
 	EndDate   int64  `form:"endDate" binding:"required"`
 }
 
+type exportDataRequest struct {
+	commonRequest
+	Format   string `form:"format" binding:"omitempty,oneof=xlsx csv parquet"` // 为空时导出 XLSX
+	Columns  string `form:"columns"`                                           // 逗号分隔的数据库列名，为空时导出全部列
+	Encoding string `form:"encoding" binding:"omitempty,oneof=utf8 gbk"`
+}
+
+type rebuildDataCoverageRequest struct {
+	ShipName string `form:"shipName"` // 为空时重建所有船舶
+}
+
+type getShiftStatsRequest struct {
+	commonRequest
+	Dimension   string `form:"dimension" binding:"omitempty,oneof=shift crew"`           // 为空时按班次统计
+	Granularity string `form:"granularity" binding:"omitempty,oneof=aggregate day week"` // 为空时返回合并的班组统计列表
+}
+
 type getOptimalShiftRequest struct {
 	commonRequest
+	Dimension     string  `form:"dimension" binding:"omitempty,oneof=shift crew"`
+	GroupBy       string  `form:"groupBy" binding:"omitempty,oneof=soil shift both"` // 按土质、不区分土质或两者分组，为空时按船舶登记的土质模型
+	Mode          string  `form:"mode" binding:"omitempty,oneof=single pareto"`      // pareto 时返回多目标的 Pareto 最优班组
+	Objectives    string  `form:"objectives"`                                        // 逗号分隔，如 production,unitEnergy,vacuumMargin
+	Weights       string  `form:"weights"`                                           // 逗号分隔的 目标:权重，如 production:0.5,unitEnergy:0.3
+	MinProduction float64 `form:"minProduction" binding:"gte=0"`
+	MaxVacuumKPa  float64 `form:"maxVacuumKPa" binding:"gte=0"`
 }
 
 type getShiftPieRequest struct {
 	commonRequest
+	Dimension string `form:"dimension" binding:"omitempty,oneof=shift crew"`
+}
+
+type getStateStatsRequest struct {
+	commonRequest
+	Dimension   string `form:"dimension" binding:"omitempty,oneof=shift crew"`
+	Granularity string `form:"granularity" binding:"omitempty,oneof=aggregate day week"`
+}
+
+type getDowntimeLogRequest struct {
+	commonRequest
+	MinDuration float64 `form:"minDuration" binding:"omitempty,min=0"` // 只返回不短于该值（分钟）的停工区间
+}
+
+type getParameterRecommendationsRequest struct {
+	commonRequest
+	Bins       int     `form:"bins" binding:"omitempty,min=2,max=100"`  // 每个参数划分的区间数，默认 10
+	MinSamples int     `form:"minSamples" binding:"omitempty,min=1"`    // 区间参与推荐所需的最少样本数，默认 30
+	Tolerance  float64 `form:"tolerance" binding:"omitempty,gt=0,lt=1"` // 与最优区间相差不超过该比例的区间一并推荐，默认 0.1
+	GroupBy    string  `form:"groupBy" binding:"omitempty,oneof=soil shift both"`
+}
+
+type getCrewCalendarRequest struct {
+	StartDate int64 `form:"startDate" binding:"required"`
+	EndDate   int64 `form:"endDate" binding:"required"`
 }
 
 type (
@@ -5,6 +5,7 @@ import (
 	"dredger/model"
 	"dredger/pkg/logger"
 	"dredger/service"
+	"errors"
 	"fmt"
 	"mime"
 	"os/exec"
@@ -12,13 +13,22 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/spf13/cast"
 
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 )
 
+// wsUpgrader 用于推送进度的 WebSocket 连接
+var wsUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
 type Handler struct {
 	svc *service.Service
 }
@@ -35,7 +45,7 @@ func (h *Handler) ImportData(c *gin.Context) {
 		return
 	}
 
-	startDate, endDate, err := parseFileName(req.File.Filename)
+	_, startDate, endDate, err := service.ParseImportFileName(req.File.Filename)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
 		return
@@ -48,25 +58,241 @@ func (h *Handler) ImportData(c *gin.Context) {
 	}
 	defer file.Close()
 
-	rows, err := h.svc.ImportData(file, req.ShipName, req.Cover, startDate, endDate)
+	opts := service.ImportOptions{
+		FileName:  req.File.Filename,
+		ShipName:  req.ShipName,
+		Cover:     req.Cover,
+		Mode:      req.Mode,
+		DryRun:    req.DryRun,
+		Uploader:  req.Uploader,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	if opts.Uploader == "" {
+		opts.Uploader = c.ClientIP()
+	}
+
+	// 预览不写库，同步返回结果；客户端断开时停止解析。已导入过的文件也可以预览，结果中给出已有的批次
+	if req.DryRun {
+		result, err := h.svc.ImportData(c.Request.Context(), file, opts, nil)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, success(result))
+		return
+	}
+
+	// 文件落盘后立即返回任务，解析与写库在后台执行
+	job, err := h.svc.SubmitImportJob(file, opts)
+	if errors.Is(err, service.ErrDuplicateImport) {
+		c.JSON(http.StatusConflict, fail(errConflict, err.Error()))
+		return
+	}
+	if errors.Is(err, service.ErrShipNotRegistered) {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(job))
+
+	logger.Logger.Infof("已提交 %s 的导入任务 %d", req.File.Filename, job.ID)
+}
+
+// 导出文件的 Content-Type
+var exportContentTypes = map[string]string{
+	service.FileFormatXLSX:    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	service.FileFormatCSV:     "text/csv",
+	service.FileFormatParquet: "application/vnd.apache.parquet",
+}
+
+func (h *Handler) ExportData(c *gin.Context) {
+	var query exportDataRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	opts := service.ExportOptions{
+		ShipName:  query.ShipName,
+		StartTime: query.StartDate,
+		EndTime:   query.EndDate,
+		Format:    query.Format,
+		Encoding:  query.Encoding,
+	}
+	if opts.Format == "" {
+		opts.Format = service.FileFormatXLSX
+	}
+	if query.Columns != "" {
+		opts.Columns = strings.Split(query.Columns, ",")
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", exportContentTypes[opts.Format])
+	header.Set("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(h.svc.ExportFileName(opts)))
+	if err := h.svc.ExportData(c.Request.Context(), c.Writer, opts); err != nil {
+		// 已经开始写文件时无法再返回 JSON，只能中断连接
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+		header.Del("Content-Type")
+		header.Del("Content-Disposition")
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+	}
+}
+
+func (h *Handler) ListImportBatches(c *gin.Context) {
+	var query listImportBatchesRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	batches, err := h.svc.ListImportBatches(query.ShipName, query.Limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, fail(errBadRequest, err.Error()))
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(batches))
+}
+
+func (h *Handler) RollbackImportBatch(c *gin.Context) {
+	var uri importBatchUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
 		return
 	}
-	c.JSON(http.StatusOK, success(rows))
 
-	logger.Logger.Infof("导入 %s 成功！", req.File.Filename)
+	batch, err := h.svc.RollbackImportBatch(uri.ID)
+	if errors.Is(err, service.ErrRollbackOverwritten) {
+		c.JSON(http.StatusConflict, fail(errConflict, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(batch))
+}
+
+func (h *Handler) ListImportJobs(c *gin.Context) {
+	var query listImportJobsRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	jobs, err := h.svc.ListImportJobs(query.ShipName, query.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(jobs))
+}
+
+func (h *Handler) GetImportJob(c *gin.Context) {
+	var uri importJobUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	job, err := h.svc.GetImportJob(uri.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(job))
+}
+
+func (h *Handler) CancelImportJob(c *gin.Context) {
+	var uri importJobUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	if err := h.svc.CancelImportJob(uri.ID); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(nil))
+}
+
+// WatchImportJob 通过 WebSocket 推送导入任务进度，任务结束后服务端关闭连接
+func (h *Handler) WatchImportJob(c *gin.Context) {
+	var uri importJobUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	current, updates, stop, err := h.svc.WatchImportJob(uri.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	defer stop()
+
+	ws, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.Logger.Errorf("升级 WebSocket 连接失败: %v", err)
+		return
+	}
+	defer ws.Close()
+
+	// 前端断开时停止订阅
+	go func() {
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				stop()
+				return
+			}
+		}
+	}()
+
+	if err = ws.WriteJSON(current); err != nil {
+		return
+	}
+	for job := range updates {
+		if err = ws.WriteJSON(job); err != nil {
+			return
+		}
+	}
+	if updates != nil {
+		// 推送任务结束后的最终状态
+		if final, err := h.svc.GetImportJob(uri.ID); err == nil {
+			_ = ws.WriteJSON(final)
+		}
+	}
+	_ = ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 }
 
 func (h *Handler) GetShiftStats(c *gin.Context) {
-	var query commonRequest
+	var query getShiftStatsRequest
 	if err := c.ShouldBindQuery(&query); err != nil {
 		logger.Logger.Errorf("请求参数有误: %v", err)
 		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
 		return
 	}
 
-	stats, err := h.svc.GetShiftStats(query.ShipName, query.StartDate, query.EndDate)
+	// 指定粒度时返回带合计和平均值的统计序列
+	if query.Granularity != "" {
+		series, err := h.svc.GetShiftStatSeries(query.ShipName, query.StartDate, query.EndDate, query.Dimension, query.Granularity)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+			return
+		}
+		c.JSON(http.StatusOK, success(series))
+		return
+	}
+
+	stats, err := h.svc.GetShiftStats(query.ShipName, query.StartDate, query.EndDate, query.Dimension)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
 		return
@@ -82,7 +308,12 @@ func (h *Handler) GetOptimalShift(c *gin.Context) {
 		return
 	}
 
-	result, err := h.svc.GetOptimalShift(query.ShipName, query.StartDate, query.EndDate)
+	if query.Mode == "pareto" {
+		h.getParetoShifts(c, query)
+		return
+	}
+
+	result, err := h.svc.GetOptimalShift(query.ShipName, query.StartDate, query.EndDate, query.Dimension, query.GroupBy)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
 		return
@@ -91,6 +322,110 @@ func (h *Handler) GetOptimalShift(c *gin.Context) {
 	c.JSON(http.StatusOK, success(result))
 }
 
+// getParetoShifts 多目标模式的最优班组分析
+func (h *Handler) getParetoShifts(c *gin.Context, query getOptimalShiftRequest) {
+	opts := service.ParetoOptions{
+		MinProduction: query.MinProduction,
+		MaxVacuumKPa:  query.MaxVacuumKPa,
+	}
+	if query.Objectives != "" {
+		opts.Objectives = strings.Split(query.Objectives, ",")
+	}
+	if query.Weights != "" {
+		opts.Weights = make(map[string]float64)
+		for _, part := range strings.Split(query.Weights, ",") {
+			objective, value, found := strings.Cut(part, ":")
+			weight, err := strconv.ParseFloat(value, 64)
+			if !found || err != nil {
+				c.JSON(http.StatusBadRequest, fail(errBadRequest, fmt.Sprintf("权重格式有误: %q，应为 目标:权重", part)))
+				return
+			}
+			opts.Weights[strings.TrimSpace(objective)] = weight
+		}
+	}
+
+	result, err := h.svc.GetParetoShifts(query.ShipName, query.StartDate, query.EndDate, query.Dimension, query.GroupBy, opts)
+	if errors.Is(err, service.ErrInvalidParetoOptions) {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(result))
+}
+
+// GetStateStats 返回各班组的运行状态时长和时间利用率
+func (h *Handler) GetStateStats(c *gin.Context) {
+	var query getStateStatsRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	stats, err := h.svc.GetStateStats(query.ShipName, query.StartDate, query.EndDate, query.Dimension, query.Granularity)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(stats))
+}
+
+// GetDowntimeLog 返回非挖泥状态的连续区间
+func (h *Handler) GetDowntimeLog(c *gin.Context) {
+	var query getDowntimeLogRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	downtimes, err := h.svc.GetDowntimeLog(query.ShipName, query.StartDate, query.EndDate, query.MinDuration)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(downtimes))
+}
+
+// GetParameterRecommendations 按土质分析施工参数与产量率、单位能耗的关系，返回推荐的参数范围
+func (h *Handler) GetParameterRecommendations(c *gin.Context) {
+	var query getParameterRecommendationsRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	opts := service.RecommendOptions{
+		Bins:       query.Bins,
+		MinSamples: query.MinSamples,
+		Tolerance:  query.Tolerance,
+		GroupBy:    query.GroupBy,
+	}
+	result, err := h.svc.GetParameterRecommendations(query.ShipName, query.StartDate, query.EndDate, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(result))
+}
+
+// GetDataGaps 返回相邻记录间隔超过船舶施工时长规则阈值的数据中断
+func (h *Handler) GetDataGaps(c *gin.Context) {
+	var query commonRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	gaps, err := h.svc.GetDataGaps(query.ShipName, query.StartDate, query.EndDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(gaps))
+}
+
 func (h *Handler) GetShipList(c *gin.Context) {
 	ships, err := h.svc.GetShipList()
 	if err != nil {
@@ -100,8 +435,141 @@ func (h *Handler) GetShipList(c *gin.Context) {
 	c.JSON(http.StatusOK, success(ships))
 }
 
+func (h *Handler) ListShips(c *gin.Context) {
+	ships, err := h.svc.ListShips()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(ships))
+}
+
+func (h *Handler) GetShip(c *gin.Context) {
+	var uri shipUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	ship, err := h.svc.GetShip(uri.Name)
+	if errors.Is(err, service.ErrShipNotRegistered) {
+		c.JSON(http.StatusNotFound, fail(errBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(ship))
+}
+
+// SaveShip 新增或修改船舶登记信息，船名以路径为准
+func (h *Handler) SaveShip(c *gin.Context) {
+	var uri shipUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	var req service.ShipConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	req.Name = uri.Name
+
+	ship, err := h.svc.SaveShip(&req)
+	if errors.Is(err, service.ErrShipHasData) {
+		c.JSON(http.StatusConflict, fail(errConflict, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	logger.Logger.Infof("已保存船舶 %s 的登记信息", ship.Name)
+	c.JSON(http.StatusOK, success(ship))
+}
+
+func (h *Handler) DeleteShip(c *gin.Context) {
+	var uri shipUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	err := h.svc.DeleteShip(uri.Name)
+	if errors.Is(err, service.ErrShipHasData) {
+		c.JSON(http.StatusConflict, fail(errConflict, err.Error()))
+		return
+	}
+	if errors.Is(err, service.ErrShipNotRegistered) {
+		c.JSON(http.StatusNotFound, fail(errBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(nil))
+}
+
+// GetCrewCalendar 返回某船一段时间内每天每个班次的值班班组
+func (h *Handler) GetCrewCalendar(c *gin.Context) {
+	var uri shipUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	var query getCrewCalendarRequest
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	calendar, err := h.svc.GetCrewCalendar(uri.Name, query.StartDate, query.EndDate)
+	if errors.Is(err, service.ErrShipNotRegistered) {
+		c.JSON(http.StatusNotFound, fail(errBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(calendar))
+}
+
+// SaveCrewAssignments 保存排班表，班组为空的记录会被删除
+func (h *Handler) SaveCrewAssignments(c *gin.Context) {
+	var uri shipUri
+	if err := c.ShouldBindUri(&uri); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	var req []*service.CrewAssignment
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	err := h.svc.SaveCrewAssignments(uri.Name, req)
+	if errors.Is(err, service.ErrShipNotRegistered) {
+		c.JSON(http.StatusNotFound, fail(errBadRequest, err.Error()))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+	logger.Logger.Infof("已保存船舶 %s 的 %d 条排班", uri.Name, len(req))
+	c.JSON(http.StatusOK, success(nil))
+}
+
 func (h *Handler) GetColumns(c *gin.Context) {
-	columns := h.svc.GetColumns(c.Param("shipName"))
+	columns, err := h.svc.GetColumns(c.Param("shipName"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
 	c.JSON(http.StatusOK, success(columns))
 }
 
@@ -113,7 +581,7 @@ func (h *Handler) GetShiftPie(c *gin.Context) {
 		return
 	}
 
-	pie, err := h.svc.GetShiftPie(query.ShipName, query.StartDate, query.EndDate)
+	pie, err := h.svc.GetShiftPie(query.ShipName, query.StartDate, query.EndDate, query.Dimension)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
 		return
@@ -169,6 +637,37 @@ func (h *Handler) GetNoneEmptyTimeRange(c *gin.Context) {
 	c.JSON(http.StatusOK, success(results))
 }
 
+func (h *Handler) GetDataCoverage(c *gin.Context) {
+	var req commonRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		logger.Logger.Errorf("请求参数有误: %v", err)
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	results, err := h.svc.GetDataCoverage(req.ShipName, req.StartDate, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(results))
+}
+
+func (h *Handler) RebuildDataCoverage(c *gin.Context) {
+	var req rebuildDataCoverageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, fail(errBadRequest, err.Error()))
+		return
+	}
+
+	result, err := h.svc.RebuildDataDates(req.ShipName)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, fail(errInternalServer, err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, success(result))
+}
+
 func (h *Handler) SetTheoryOptimal(c *gin.Context) {
 	var req setTheoryOptimalRequest
 	// 从请求体中绑定 JSON 数据
@@ -339,7 +838,8 @@ func (h *Handler) OpenFile(c *gin.Context) {
 	}
 	// 只允许 pys 目录下
 	root, _ := filepath.Abs("./pys")
-	if !strings.HasPrefix(abs, root) {
+	// 导入生成的拒绝文件在 dataDir 下，部署时不一定位于工作目录的 ./pys 中
+	if !strings.HasPrefix(abs, root) && !isUnderDir(abs, h.svc.RejectDir()) {
 		c.JSON(http.StatusForbidden, fail(errBadRequest, "path not allowed"))
 		return
 	}
@@ -352,6 +852,12 @@ func (h *Handler) OpenFile(c *gin.Context) {
 	c.JSON(http.StatusOK, success(nil))
 }
 
+// isUnderDir path 是否位于 dir 目录下（不含 dir 本身）
+func isUnderDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 func (h *Handler) ServeFile(c *gin.Context) {
 	var req serveReq
 	if err := c.ShouldBindQuery(&req); err != nil {
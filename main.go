@@ -5,11 +5,10 @@ import (
 	"dredger/handler"
 	"dredger/model" // 导入 model 包
 	"dredger/pkg/conf"
+	"dredger/pkg/database"
 	"dredger/pkg/logger"
 	"dredger/service"
 	"encoding/binary"
-	"errors"
-	"fmt"
 	"io"
 	"log"
 	"net"
@@ -17,11 +16,11 @@ import (
 	"os"
 	"sync" // 导入 sync 包
 	"time"
+	_ "time/tzdata" // Windows 等没有时区数据库的环境也能加载 timezone 配置中的时区
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	gormLogger "gorm.io/gorm/logger"
 )
@@ -36,33 +35,6 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-type DbMigration struct {
-	MigrationName string    `gorm:"primaryKey"` // 迁移名称，作为主键
-	AppliedAt     time.Time // 应用时间
-}
-
-func runSqlFile(db *gorm.DB, filepath string) error {
-	log.Printf("准备执行SQL文件: %s\n", filepath)
-	sqlBytes, err := os.ReadFile(filepath)
-	if err != nil {
-		return fmt.Errorf("无法读取SQL文件 %s: %w", filepath, err)
-	}
-
-	sqlScript := string(sqlBytes)
-
-	// 在一个事务中执行整个脚本
-	// 这要求MySQL DSN中必须包含 &multiStatements=true
-	tx := db.Begin()
-	if err = tx.Exec(sqlScript).Error; err != nil {
-		log.Printf("执行SQL脚本失败，正在回滚: %v\n", err)
-		tx.Rollback()
-		return fmt.Errorf("执行SQL脚本失败: %w", err)
-	}
-
-	log.Printf("SQL脚本执行成功，正在提交事务: %s\n", filepath)
-	return tx.Commit().Error
-}
-
 // SensorData 定义了发送给前端的完整数据结构
 // 该结构严格参照 dredger_data_hl.gen.go 文件生成，确保字段和json标签完全一致
 type SensorData struct {
@@ -260,8 +232,14 @@ func handleSensorTCP(ws *websocket.Conn, addr string) {
 	// 准备协议中定义的发送指令
 	command := []byte{0x40, 0xFF, 0x00, 0x00, 0x0D, 0x0A}
 
-	// 获取“华安龙”的配置，用于后续计算
-	shipCfg := service.GetCfg("华安龙")
+	// 获取接入传感器的船舶的登记信息，用于解析数据点和计算预估真空度
+	shipName := conf.Conf.GetString("sensor.ship")
+	ship, err := service.LookupShip(shipName)
+	if err != nil {
+		log.Printf("failed to load ship %s: %v", shipName, err)
+		ws.WriteJSON(gin.H{"error": "Ship is not registered"})
+		return
+	}
 
 	// 使用 Ticker 每秒钟触发一次数据请求
 	ticker := time.NewTicker(1 * time.Second)
@@ -316,152 +294,20 @@ func handleSensorTCP(ws *websocket.Conn, addr string) {
 		}
 
 		// 4. 填充模型并计算预估真空度
-		// 创建一个 model 实例用于存放解析后的数据
+		// 创建一个 model 实例用于存放解析后的数据，按船舶登记的点位填充
 		dredgerData := &model.DredgerDataHl{}
-		// 协议中的AI数据点索引从327开始，所以需要减去327来对应到数组索引
-		// 更新了长度检查，以确保所有需要的字段都在范围内
-		if len(aiFloats) > (475 - 327) {
-			// --- 填充所有在 SensorData 结构体中定义的字段 ---
-			dredgerData.LeftEarDraft = float64(aiFloats[327-327])
-			dredgerData.UnderwaterPumpSuctionSealPressure = float64(aiFloats[328-327])
-			dredgerData.UnderwaterPumpShaftSealPressure = float64(aiFloats[329-327])
-			dredgerData.MudPump1ShaftSealPressure = float64(aiFloats[330-327])
-			dredgerData.MudPump1SuctionSealPressure = float64(aiFloats[331-327])
-			dredgerData.MudPump2SuctionSealPressure = float64(aiFloats[332-327])
-			dredgerData.MudPump2ShaftSealPressure = float64(aiFloats[333-327])
-			dredgerData.RightEarDraft = float64(aiFloats[334-327])
-			dredgerData.LeftAnchorRodAngle = float64(aiFloats[335-327])
-			dredgerData.RightAnchorRodAngle = float64(aiFloats[336-327])
-			dredgerData.MudPump1Speed = float64(aiFloats[337-327])
-			dredgerData.MudPump2Speed = float64(aiFloats[338-327])
-			dredgerData.UnderwaterPumpSpeed = float64(aiFloats[339-327])
-			dredgerData.FlowVelocity = float64(aiFloats[340-327])
-			dredgerData.Density = float64(aiFloats[341-327])
-			dredgerData.UnderwaterPumpMotorCurrent = float64(aiFloats[342-327])
-			dredgerData.UnderwaterPumpMotorVoltage = float64(aiFloats[343-327])
-			dredgerData.UnderwaterPumpTorque = float64(aiFloats[344-327])
-			dredgerData.UnderwaterPumpMotorSpeed = float64(aiFloats[345-327])
-			dredgerData.MudPump2DieselLoad = float64(aiFloats[346-327])
-			dredgerData.MudPump2DieselSpeed = float64(aiFloats[347-327])
-			dredgerData.MudPump1DieselLoad = float64(aiFloats[348-327])
-			dredgerData.MudPump1DieselSpeed = float64(aiFloats[349-327])
-			dredgerData.HydraulicPumpDieselLoad = float64(aiFloats[350-327])
-			dredgerData.HydraulicPumpDieselSpeed = float64(aiFloats[351-327])
-			dredgerData.GateValveFlushPressure = float64(aiFloats[352-327])
-			dredgerData.CutterBearingFlushPressure = float64(aiFloats[353-327])
-			dredgerData.TrolleyHydraulicCylinderPressure = float64(aiFloats[354-327])
-			dredgerData.SteelPileHydraulicCylinderPressure = float64(aiFloats[355-327])
-			dredgerData.GateValveSystemPressure = float64(aiFloats[356-327])
-			dredgerData.RightTransversePressure = float64(aiFloats[357-327])
-			dredgerData.LeftTransversePressure = float64(aiFloats[358-327])
-			dredgerData.TrolleyTravel = float64(aiFloats[359-327])
-			dredgerData.LeftTransverseSpeed = float64(aiFloats[360-327])
-			dredgerData.RightTransverseSpeed = float64(aiFloats[361-327])
-			dredgerData.CutterSpeed = float64(aiFloats[362-327])
-			dredgerData.MudPump1DischargePressure = float64(aiFloats[363-327])
-			dredgerData.MudPump2DischargePressure = float64(aiFloats[364-327])
-			dredgerData.UnderwaterPumpDischargePressure = float64(aiFloats[365-327])
-			dredgerData.UnderwaterPumpSuctionVacuum = float64(aiFloats[366-327])
-			dredgerData.BridgeAngle = float64(aiFloats[367-327])
-			dredgerData.CompassAngle = float64(aiFloats[368-327])
-			dredgerData.Gps1X = float64(aiFloats[369-327])
-			dredgerData.Gps1Y = float64(aiFloats[370-327])
-			dredgerData.Gps1Heading = float64(aiFloats[371-327])
-			dredgerData.Gps1Speed = float64(aiFloats[372-327])
-			dredgerData.TideLevel = float64(aiFloats[373-327])
-			dredgerData.WaterDensity = float64(aiFloats[374-327])
-			dredgerData.FieldSlurryDensity = float64(aiFloats[375-327])
-			dredgerData.TrimAngle = float64(aiFloats[376-327])
-			dredgerData.PitchAngle = float64(aiFloats[377-327])
-			dredgerData.CompassRadian = float64(aiFloats[378-327])
-			dredgerData.Gps1Latitude = float64(aiFloats[379-327])
-			dredgerData.Gps1Longitude = float64(aiFloats[380-327])
-			dredgerData.EarDraft = float64(aiFloats[381-327])
-			dredgerData.TransverseSpeed = float64(aiFloats[382-327])
-			// 383: 绞刀转速 is a duplicate of 362, skipping
-			dredgerData.HourlyOutputRate = float64(aiFloats[384-327])
-			dredgerData.RotationRadius = float64(aiFloats[385-327])
-			dredgerData.CutterX = float64(aiFloats[386-327])
-			dredgerData.CutterY = float64(aiFloats[387-327])
-			// Protocol index 388 'PreviousShiftProduction' does not exist in the model. Mapping to CurrentShiftOutput.
-			dredgerData.CurrentShiftOutput = float64(aiFloats[388-327])
-			dredgerData.CurrentShiftOutputRate = float64(aiFloats[389-327])
-			dredgerData.OutletFlowVelocity = float64(aiFloats[390-327])
-			dredgerData.LeftTransverseTorque = float64(aiFloats[391-327])
-			dredgerData.CutterTorque = float64(aiFloats[392-327])
-			dredgerData.Concentration = float64(aiFloats[393-327])
-			dredgerData.FlowRate = float64(aiFloats[394-327])
-			dredgerData.RightTransverseTorque = float64(aiFloats[395-327])
-			dredgerData.LeftAnchorWinchSpeed = float64(aiFloats[396-327])
-			dredgerData.LeftAnchorWinchTorque = float64(aiFloats[397-327])
-			dredgerData.RightAnchorWinchSpeed = float64(aiFloats[398-327])
-			dredgerData.RightAnchorWinchTorque = float64(aiFloats[399-327])
-			dredgerData.LeftSwingWinchSpeed = float64(aiFloats[400-327])
-			dredgerData.LeftSwingWinchTorque = float64(aiFloats[401-327])
-			dredgerData.RightSwingWinchSpeed = float64(aiFloats[402-327])
-			dredgerData.RightSwingWinchTorque = float64(aiFloats[403-327])
-			dredgerData.BridgeWinchSpeed = float64(aiFloats[404-327])
-			dredgerData.BridgeWinchTorque = float64(aiFloats[405-327])
-			dredgerData.BridgeDepth = float64(aiFloats[406-327])
-			dredgerData.TransverseDirection = int32(aiFloats[407-327])
-			dredgerData.CutterCuttingAngle = float64(aiFloats[408-327])
-			dredgerData.UnderwaterPumpPower = float64(aiFloats[409-327])
-			dredgerData.MudPump1Power = float64(aiFloats[410-327])
-			dredgerData.MudPump2Power = float64(aiFloats[411-327])
-			dredgerData.UnderwaterPumpShaftPower = float64(aiFloats[412-327])
-			dredgerData.MudPump1ShaftPower = float64(aiFloats[413-327])
-			dredgerData.MudPump2ShaftPower = float64(aiFloats[414-327])
-			dredgerData.UnderwaterPumpEfficiency = float64(aiFloats[415-327])
-			dredgerData.MudPump1Efficiency = float64(aiFloats[416-327])
-			dredgerData.MudPump2Efficiency = float64(aiFloats[417-327])
-			dredgerData.PipelineAverageConcentration = float64(aiFloats[418-327])
-			dredgerData.PipelineTotalDamping = float64(aiFloats[419-327])
-			dredgerData.DensityForecast = float64(aiFloats[420-327])
-			dredgerData.CuttingThickness = float64(aiFloats[421-327])
-			dredgerData.ShipDirection = float64(aiFloats[422-327])
-			dredgerData.Gps1SignalQuality = float64(aiFloats[423-327])
-			dredgerData.Gps2SignalQuality = float64(aiFloats[424-327])
-			dredgerData.DeckPump1CoverSealPressure = float64(aiFloats[427-327])
-			dredgerData.DeckPump2CoverSealPressure = float64(aiFloats[428-327])
-			dredgerData.DeckPump1ShaftSealPressure = float64(aiFloats[429-327])
-			dredgerData.DeckPump2ShaftSealPressure = float64(aiFloats[430-327])
-			dredgerData.CutterDriveGateValveFlushPressure = float64(aiFloats[431-327])
-			dredgerData.CutterBearingFlushPressureJkt = float64(aiFloats[432-327])
-			dredgerData.UnderwaterPumpCoverSealPressure = float64(aiFloats[433-327])
-			dredgerData.UnderwaterPumpShaftSealPressureJkt = float64(aiFloats[434-327])
-			dredgerData.DeckPump1GearboxOilTemperature = float64(aiFloats[435-327])
-			dredgerData.DeckPump1GearboxOilPressure = float64(aiFloats[436-327])
-			dredgerData.DeckPump2GearboxOilTemperature = float64(aiFloats[437-327])
-			dredgerData.DeckPump2GearboxOilPressure = float64(aiFloats[438-327])
-			dredgerData.CutterDriveGearboxOilTemperature = float64(aiFloats[439-327])
-			dredgerData.CutterDriveGearboxOilPressure = float64(aiFloats[440-327])
-			dredgerData.CutterDriveGearboxOilSaturation = float64(aiFloats[441-327])
-			dredgerData.UnderwaterPumpGearboxOilTemperature = float64(aiFloats[442-327])
-			dredgerData.UnderwaterPumpGearboxOilPressure = float64(aiFloats[443-327])
-			dredgerData.UnderwaterPumpGearboxOilSaturation = float64(aiFloats[444-327])
-			dredgerData.FuelTank40Level = float64(aiFloats[445-327])
-			dredgerData.MerFuelDailyTankLevel = float64(aiFloats[453-327])
-			dredgerData.FuelTank3Level = float64(aiFloats[461-327])
-			dredgerData.LubricatingOilTank5Level = float64(aiFloats[462-327])
-			dredgerData.HydraulicOilTank7Level = float64(aiFloats[463-327])
-			dredgerData.AuxiliaryFuelDailyTankLevel = float64(aiFloats[464-327])
-			dredgerData.FuelTank13Level = float64(aiFloats[465-327])
-			dredgerData.FuelTank3ALevel = float64(aiFloats[466-327])
-			dredgerData.FuelTank4Level = float64(aiFloats[469-327])
-			dredgerData.SewageTank6Level = float64(aiFloats[470-327])
-			dredgerData.FreshwaterTank8Level = float64(aiFloats[471-327])
-			dredgerData.DirtyOilTank11Level = float64(aiFloats[472-327])
-			dredgerData.FuelTank12Level = float64(aiFloats[473-327])
-			dredgerData.FreshwaterTank26Level = float64(aiFloats[474-327])
-			dredgerData.FuelTank4ALevel = float64(aiFloats[475-327])
-
-		} else {
-			log.Printf("received AI data length (%d) is not enough", len(aiFloats))
+		missing, err := ship.DecodeSensorFrame(aiFloats, dredgerData)
+		if err != nil {
+			log.Printf("error decoding AI data: %v", err)
+			return
+		}
+		if len(missing) > 0 {
+			log.Printf("received AI data length (%d) is not enough, missing %d points", len(aiFloats), len(missing))
 			continue
 		}
 
 		// 调用service计算预估真空度
-		predictedVacuum := service.CalcVacuumKPaFromHL(dredgerData, shipCfg)
+		predictedVacuum := ship.EstimateVacuumKPa(dredgerData)
 
 		// 5. 准备发送给前端的数据
 		// 直接通过 dredgerData 构建 sensorData，确保字段一致
@@ -618,76 +464,23 @@ func main() {
 	conf.InitConf("./dredger.yaml")
 	logger.InitLogger("dredger")
 
-	host := conf.Conf.GetString("database.host")
-	password := conf.Conf.GetString("database.password")
 	var err error
-	dsn := fmt.Sprintf("root:%s@tcp(%s)/dredger?charset=utf8mb4&parseTime=True&loc=Local&multiStatements=true",
-		password,
-		host,
-	)
-	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: gormLogger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), gormLogger.Config{
-			SlowThreshold: time.Second,
-			LogLevel:      gormLogger.Info,
-			Colorful:      true,
-		}),
-	})
+	db, err = database.Open(gormLogger.Info)
 	if err != nil {
 		logger.Logger.Errorf("failed to connect database: %v", err)
 		return
 	}
-
-	// 1. 自动迁移（创建/更新）用于跟踪版本的 DbMigration 表
-	if err := db.AutoMigrate(&DbMigration{}); err != nil {
-		log.Fatalf("创建迁移记录表(db_migrations)失败: %v", err)
+	if err = database.Migrate(db, service.Migrations()...); err != nil {
+		log.Fatal(err)
 	}
 
-	// 2. 定义 soil_regions.sql 脚本的唯一迁移名称
-	const soilMigrationName = "init_soil_regions_v1" // v1代表版本1
-	var migrationRecord DbMigration
-
-	// 3. 检查这个迁移是否已经执行过
-	err = db.Where("migration_name = ?", soilMigrationName).First(&migrationRecord).Error
-
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// 记录未找到，说明是第一次运行，需要执行SQL文件
-		log.Printf("未找到迁移记录 '%s'，准备执行 soil_regions.sql...", soilMigrationName)
-
-		// 4. 在事务中执行 SQL 文件
-		if err := runSqlFile(db, "gen/soil_regions.sql"); err != nil {
-			// 如果执行失败，程序将终止
-			log.Fatalf("执行迁移 '%s' (soil_regions.sql) 失败: %v", soilMigrationName, err)
-		}
-
-		// 5. 将迁移记录写入数据库，标记为已完成
-		if err := db.Create(&DbMigration{MigrationName: soilMigrationName, AppliedAt: time.Now()}).Error; err != nil {
-			log.Fatalf("记录迁移 '%s' 到数据库失败: %v", soilMigrationName, err)
+	svc := service.NewService(db)
+	svc.InterruptStaleImportJobs()
+	if dir := conf.Conf.GetString("inbox.dir"); dir != "" {
+		if err := svc.StartImportInbox(dir); err != nil {
+			logger.Logger.Errorf("启动导入收件目录监听失败: %v", err)
 		}
-
-		log.Printf("成功应用并记录迁移: '%s'", soilMigrationName)
-
-	} else if err != nil {
-		// 如果是其他数据库错误，终止程序
-		log.Fatalf("检查迁移 '%s' 时出错: %v", soilMigrationName, err)
-	} else {
-		// 记录已存在，说明已执行过，跳过
-		log.Printf("迁移 '%s' 已在 %s 应用过，本次启动跳过。", migrationRecord.MigrationName, migrationRecord.AppliedAt.Format(time.RFC3339))
 	}
-
-	log.Println("正在自动迁移所有业务数据表...")
-	err = db.AutoMigrate(
-		&model.DataDate{},           // 对应 model/data_date.gen.go
-		&model.DredgerDatum{},       // 对应 model/dredger_data.gen.go
-		&model.DredgerDataHl{},      // 对应 model/dredger_data_hl.gen.go
-		&model.TheoryOptimalParam{}, // 对应 model/theory_optimal_params.gen.go
-		&model.SoilRegion{},         // 对应 model/soil_regions.gen.go (迁移表结构)
-	)
-	if err != nil {
-		log.Fatalf("自动迁移业务模型失败: %v", err)
-	}
-	log.Println("业务数据表迁移完成。")
-
-	svc := service.NewService(db)
 	r := SetupRouter(svc)
 	_ = r.Run(":12580")
 }
@@ -705,16 +498,34 @@ func SetupRouter(svc *service.Service) *gin.Engine {
 	api := r.Group("/v1")
 	{
 		api.POST("/data/import", h.ImportData)
+		api.GET("/data/import/jobs", h.ListImportJobs)
+		api.GET("/data/import/jobs/:id", h.GetImportJob)
+		api.POST("/data/import/jobs/:id/cancel", h.CancelImportJob)
+		api.GET("/data/import/batches", h.ListImportBatches)
+		api.GET("/data/export", h.ExportData)
+		api.POST("/data/import/batches/:id/rollback", h.RollbackImportBatch)
 		api.GET("/shifts/statistics", h.GetShiftStats)
 		api.GET("/data/column/list/:shipName", h.GetColumns)
 		api.GET("/ship/list", h.GetShipList)
+		api.GET("/ships", h.ListShips)
+		api.GET("/ships/:name", h.GetShip)
+		api.PUT("/ships/:name", h.SaveShip)
+		api.DELETE("/ships/:name", h.DeleteShip)
+		api.GET("/ships/:name/crews", h.GetCrewCalendar)
+		api.PUT("/ships/:name/crews", h.SaveCrewAssignments)
 		api.GET("/shifts/optimal", h.GetOptimalShift)
+		api.GET("/shifts/states", h.GetStateStats)
 		api.GET("/data/replay/:columnName", h.GetHistoryData)
 		api.GET("data/timerange/global", h.GetGlobalTimeRange)
 		api.GET("data/timerange/nonempty", h.GetNoneEmptyTimeRange)
+		api.GET("/data/coverage", h.GetDataCoverage)
+		api.POST("/data/coverage/rebuild", h.RebuildDataCoverage)
+		api.GET("/data/gaps", h.GetDataGaps)
+		api.GET("/data/downtime", h.GetDowntimeLog)
 		api.POST("/data/theory/optimal", h.SetTheoryOptimal)
 		api.GET("/data/theory/optimal", h.GetTheoryOptimal)
 		api.GET("/shifts/parameters", h.GetAllShiftParameters)
+		api.GET("/shifts/parameters/recommended", h.GetParameterRecommendations)
 		api.POST("/demos/run", h.RunDemo)    // 上传+执行+返回新增文件
 		api.POST("/files/open", h.OpenFile)  // Windows 打开文件
 		api.GET("/files/serve", h.ServeFile) // 预览直链：/v1/files/serve?path=...
@@ -726,6 +537,7 @@ func SetupRouter(svc *service.Service) *gin.Engine {
 		api.GET("/ws/sensor", func(c *gin.Context) {
 			handleConnections(c.Writer, c.Request)
 		})
+		api.GET("/ws/import/jobs/:id", h.WatchImportJob)
 	}
 
 	return r
@@ -8,9 +8,12 @@ const TableNameDataDate = "data_date"
 
 // DataDate mapped from table <data_date>
 type DataDate struct {
-	ID       int32  `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
-	ShipName string `gorm:"column:ship_name;not null;type:varchar(191)" json:"ship_name"`
-	Date     int64  `gorm:"column:date" json:"date"`
+	ID          int32  `gorm:"column:id;primaryKey;autoIncrement:true" json:"id"`
+	ShipName    string `gorm:"column:ship_name;not null;type:varchar(191);uniqueIndex:uk_ship_date,priority:1" json:"ship_name"`
+	Date        int64  `gorm:"column:date;uniqueIndex:uk_ship_date,priority:2" json:"date"`
+	RecordCount int64  `gorm:"column:record_count;not null;default:0;comment:当天数据行数" json:"record_count"` // 当天数据行数
+	FirstTime   int64  `gorm:"column:first_time;comment:当天第一条数据的时间" json:"first_time"`                    // 当天第一条数据的时间
+	LastTime    int64  `gorm:"column:last_time;comment:当天最后一条数据的时间" json:"last_time"`                     // 当天最后一条数据的时间
 }
 
 // TableName DataDate's table name
@@ -9,8 +9,8 @@ const TableNameDredgerDatum = "dredger_data"
 // DredgerDatum 敏龙绞吸式挖泥船施工数据表
 type DredgerDatum struct {
 	ID                                int64   `gorm:"column:id;primaryKey;autoIncrement:true;comment:主键id" json:"id"`                                               // 主键id
-	ShipName                          string  `gorm:"column:ship_name;not null;type:varchar(191);comment:船名" json:"ship_name"`                                      // 船名                                                       // 船名
-	RecordTime                        int64   `gorm:"column:record_time;comment:时间" json:"record_time"`                                                             // 时间
+	ShipName                          string  `gorm:"column:ship_name;not null;type:varchar(191);uniqueIndex:uk_ship_time,priority:1;comment:船名" json:"ship_name"`  // 船名                                                       // 船名
+	RecordTime                        int64   `gorm:"column:record_time;uniqueIndex:uk_ship_time,priority:2;comment:时间" json:"record_time"`                         // 时间
 	LeftEarDraft                      float64 `gorm:"column:left_ear_draft;comment:左耳轴吃水" json:"left_ear_draft"`                                                    // 左耳轴吃水
 	UnderwaterPumpSuctionSealPressure float64 `gorm:"column:underwater_pump_suction_seal_pressure;comment:水下泵吸入端封水压力" json:"underwater_pump_suction_seal_pressure"` // 水下泵吸入端封水压力
 	UnderwaterPumpShaftSealPressure   float64 `gorm:"column:underwater_pump_shaft_seal_pressure;comment:水下泵轴端封水压力" json:"underwater_pump_shaft_seal_pressure"`      // 水下泵轴端封水压力
@@ -125,6 +125,7 @@ type DredgerDatum struct {
 	CurrentWorklineEndYDpm            float64 `gorm:"column:current_workline_end_y_dpm;comment:当前工作线终点y(自DPM)" json:"current_workline_end_y_dpm"`                   // 当前工作线终点y(自DPM)
 	PreviousCutterDepthDpm            float64 `gorm:"column:previous_cutter_depth_dpm;comment:绞刀头点上一次的深度(自DPM)" json:"previous_cutter_depth_dpm"`                   // 绞刀头点上一次的深度(自DPM)
 	ShipDeviationAngle                float64 `gorm:"column:ship_deviation_angle;comment:船体偏移工作线角度" json:"ship_deviation_angle"`                                    // 船体偏移工作线角度
+	BatchID                           int64   `gorm:"column:batch_id;index:idx_batch;comment:导入批次ID" json:"batch_id"`                                               // 导入批次ID
 }
 
 // TableName DredgerDatum's table name
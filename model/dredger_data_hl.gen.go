@@ -9,8 +9,8 @@ const TableNameDredgerDataHl = "dredger_data_hl"
 // DredgerDataHl 华安龙绞吸式挖泥船施工数据表
 type DredgerDataHl struct {
 	ID                                  int64   `gorm:"column:id;primaryKey;autoIncrement:true;comment:主键id" json:"id"`                                                            // 主键id
-	ShipName                            string  `gorm:"column:ship_name;not null;type:varchar(191);comment:船名" json:"ship_name"`                                                   // 船名
-	RecordTime                          int64   `gorm:"column:record_time;comment:时间" json:"record_time"`                                                                          // 时间
+	ShipName                            string  `gorm:"column:ship_name;not null;type:varchar(191);uniqueIndex:uk_ship_time,priority:1;comment:船名" json:"ship_name"`               // 船名
+	RecordTime                          int64   `gorm:"column:record_time;uniqueIndex:uk_ship_time,priority:2;comment:时间" json:"record_time"`                                      // 时间
 	LeftEarDraft                        float64 `gorm:"column:left_ear_draft;comment:左耳轴吃水(m)" json:"left_ear_draft"`                                                              // 左耳轴吃水(m)
 	UnderwaterPumpSuctionSealPressure   float64 `gorm:"column:underwater_pump_suction_seal_pressure;comment:水下泵吸入端封水压力(bar)" json:"underwater_pump_suction_seal_pressure"`         // 水下泵吸入端封水压力(bar)
 	UnderwaterPumpShaftSealPressure     float64 `gorm:"column:underwater_pump_shaft_seal_pressure;comment:水下泵轴端封水压力(bar)" json:"underwater_pump_shaft_seal_pressure"`              // 水下泵轴端封水压力(bar)
@@ -141,6 +141,7 @@ type DredgerDataHl struct {
 	FuelTank12Level                     float64 `gorm:"column:fuel_tank_12_level;comment:[SBCR]燃油舱12液位(m)" json:"fuel_tank_12_level"`                                              // [SBCR]燃油舱12液位(m)
 	FreshwaterTank26Level               float64 `gorm:"column:freshwater_tank_26_level;comment:[SBCR]淡水舱26液位(m)" json:"freshwater_tank_26_level"`                                  // [SBCR]淡水舱26液位(m)
 	FuelTank4ALevel                     float64 `gorm:"column:fuel_tank_4a_level;comment:[SBCR]燃油舱4A液位显示状态(m)" json:"fuel_tank_4a_level"`                                          // [SBCR]燃油舱4A液位显示状态(m)
+	BatchID                             int64   `gorm:"column:batch_id;index:idx_batch;comment:导入批次ID" json:"batch_id"`                                                            // 导入批次ID
 }
 
 // TableName DredgerDataHl's table name
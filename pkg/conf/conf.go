@@ -34,4 +34,13 @@ func setDefaultConfig(v *viper.Viper) {
 	v.SetDefault("log.expire", 3)
 	v.SetDefault("log.limit", 15)
 	v.SetDefault("log.stdout", true)
+	v.SetDefault("database.user", "root")
+	v.SetDefault("database.name", "dredger")
+	v.SetDefault("import.required", []string{"record_time", "flow_rate", "concentration"})
+	v.SetDefault("import.rejectSamples", 100)
+	v.SetDefault("import.previewSamples", 10)
+	v.SetDefault("inbox.mode", "skip")
+	v.SetDefault("inbox.settle", "3s")
+	v.SetDefault("timezone.default", "")
+	v.SetDefault("sensor.ship", "华安龙")
 }
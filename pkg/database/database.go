@@ -0,0 +1,191 @@
+package database
+
+import (
+	"dredger/model"
+	"dredger/pkg/conf"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// Open 按 dredger.yaml 中的 database 配置连接 MySQL，服务和 dredgerctl 共用
+func Open(logLevel gormLogger.LogLevel) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?charset=utf8mb4&parseTime=True&loc=Local&multiStatements=true",
+		conf.Conf.GetString("database.user"),
+		conf.Conf.GetString("database.password"),
+		conf.Conf.GetString("database.host"),
+		conf.Conf.GetString("database.name"),
+	)
+	return gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger: gormLogger.New(log.New(os.Stdout, "\r\n", log.LstdFlags), gormLogger.Config{
+			SlowThreshold: time.Second,
+			LogLevel:      logLevel,
+			Colorful:      true,
+		}),
+	})
+}
+
+// Migrate 执行一次性迁移并自动迁移所有业务数据表，再依次执行 migrations，已执行过的迁移会被跳过
+func Migrate(db *gorm.DB, migrations ...Migration) error {
+	// 1. 自动迁移（创建/更新）用于跟踪版本的 DbMigration 表
+	if err := db.AutoMigrate(&DbMigration{}); err != nil {
+		return fmt.Errorf("创建迁移记录表(db_migrations)失败: %w", err)
+	}
+
+	// 2. 执行一次性迁移，已执行过的迁移会被跳过
+	if err := applyMigration(db, "init_soil_regions_v1", func() error { // v1代表版本1
+		return runSqlFile(db, "gen/soil_regions.sql")
+	}); err != nil {
+		return err
+	}
+	// 建立 (ship_name, record_time) 唯一索引之前先清理重复数据，必须在 AutoMigrate 之前执行
+	if err := applyMigration(db, "dedupe_dredger_data_v1", func() error {
+		return dedupeDredgerData(db)
+	}); err != nil {
+		return err
+	}
+
+	// 建立 (ship_name, date) 唯一索引之前先清理 data_date 中的重复记录
+	if err := applyMigration(db, "dedupe_data_date_v1", func() error {
+		return dedupeDataDate(db)
+	}); err != nil {
+		return err
+	}
+
+	log.Println("正在自动迁移所有业务数据表...")
+	err := db.AutoMigrate(
+		&model.DataDate{},           // 对应 model/data_date.gen.go
+		&model.DredgerDatum{},       // 对应 model/dredger_data.gen.go
+		&model.DredgerDataHl{},      // 对应 model/dredger_data_hl.gen.go
+		&model.TheoryOptimalParam{}, // 对应 model/theory_optimal_params.gen.go
+		&model.SoilRegion{},         // 对应 model/soil_regions.gen.go (迁移表结构)
+		&model.ImportJob{},          // 对应 model/import_jobs.gen.go
+		&model.ImportBatch{},        // 对应 model/import_batches.gen.go
+		&model.Ship{},               // 对应 model/ships.gen.go
+		&model.CrewAssignment{},     // 对应 model/crew_assignments.gen.go
+	)
+	if err != nil {
+		return fmt.Errorf("自动迁移业务模型失败: %w", err)
+	}
+	log.Println("业务数据表迁移完成。")
+
+	// 已有的未回滚批次补写 active_hash，同一文件有多个未回滚批次时只保留最新的一个
+	if err = applyMigration(db, "backfill_import_batch_active_hash_v1", func() error {
+		return db.Exec("UPDATE `import_batches` b JOIN (SELECT MAX(id) AS id FROM `import_batches` WHERE status = 'active' GROUP BY file_hash) k ON b.id = k.id SET b.active_hash = b.file_hash").Error
+	}); err != nil {
+		return err
+	}
+
+	// 依赖业务逻辑的迁移由上层传入，按顺序执行
+	for _, m := range migrations {
+		if err = applyMigration(db, m.Name, func() error { return m.Apply(db) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Migration 由上层（如 service）提供的一次性迁移，在业务数据表迁移完成后执行
+type Migration struct {
+	Name  string
+	Apply func(db *gorm.DB) error
+}
+
+type DbMigration struct {
+	MigrationName string    `gorm:"primaryKey"` // 迁移名称，作为主键
+	AppliedAt     time.Time // 应用时间
+}
+
+func runSqlFile(db *gorm.DB, filepath string) error {
+	log.Printf("准备执行SQL文件: %s\n", filepath)
+	sqlBytes, err := os.ReadFile(filepath)
+	if err != nil {
+		return fmt.Errorf("无法读取SQL文件 %s: %w", filepath, err)
+	}
+
+	sqlScript := string(sqlBytes)
+
+	// 在一个事务中执行整个脚本
+	// 这要求MySQL DSN中必须包含 &multiStatements=true
+	tx := db.Begin()
+	if err = tx.Exec(sqlScript).Error; err != nil {
+		log.Printf("执行SQL脚本失败，正在回滚: %v\n", err)
+		tx.Rollback()
+		return fmt.Errorf("执行SQL脚本失败: %w", err)
+	}
+
+	log.Printf("SQL脚本执行成功，正在提交事务: %s\n", filepath)
+	return tx.Commit().Error
+}
+
+// applyMigration 检查迁移记录，未执行过的迁移执行 apply 后写入 db_migrations
+func applyMigration(db *gorm.DB, name string, apply func() error) error {
+	var migrationRecord DbMigration
+	err := db.Where("migration_name = ?", name).First(&migrationRecord).Error
+	if err == nil {
+		// 记录已存在，说明已执行过，跳过
+		log.Printf("迁移 '%s' 已在 %s 应用过，本次启动跳过。", migrationRecord.MigrationName, migrationRecord.AppliedAt.Format(time.RFC3339))
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("检查迁移 '%s' 时出错: %w", name, err)
+	}
+
+	log.Printf("未找到迁移记录 '%s'，准备执行...", name)
+	if err = apply(); err != nil {
+		return fmt.Errorf("执行迁移 '%s' 失败: %w", name, err)
+	}
+	if err = db.Create(&DbMigration{MigrationName: name, AppliedAt: time.Now()}).Error; err != nil {
+		return fmt.Errorf("记录迁移 '%s' 到数据库失败: %w", name, err)
+	}
+	log.Printf("成功应用并记录迁移: '%s'", name)
+	return nil
+}
+
+// dedupeDredgerData 删除同一船舶同一时间的重复记录（保留 id 最大、即最后导入的一条），
+// 并删除被唯一索引 uk_ship_time 取代的普通索引 idx_ship_time
+func dedupeDredgerData(db *gorm.DB) error {
+	for _, m := range []any{&model.DredgerDatum{}, &model.DredgerDataHl{}} {
+		if !db.Migrator().HasTable(m) {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+		res := db.Exec(fmt.Sprintf(
+			"DELETE d FROM `%[1]s` d JOIN `%[1]s` k ON d.ship_name = k.ship_name AND d.record_time = k.record_time AND d.id < k.id",
+			table))
+		if res.Error != nil {
+			return fmt.Errorf("清理 %s 重复数据失败: %w", table, res.Error)
+		}
+		log.Printf("已删除 %s 中 %d 条重复数据", table, res.RowsAffected)
+
+		if db.Migrator().HasIndex(m, "idx_ship_time") {
+			if err := db.Migrator().DropIndex(m, "idx_ship_time"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dedupeDataDate 删除 data_date 中同一船舶同一天的重复记录，保留 id 最大的一条
+func dedupeDataDate(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&model.DataDate{}) {
+		return nil
+	}
+	res := db.Exec("DELETE d FROM `data_date` d JOIN `data_date` k ON d.ship_name = k.ship_name AND d.date = k.date AND d.id < k.id")
+	if res.Error != nil {
+		return fmt.Errorf("清理 data_date 重复数据失败: %w", res.Error)
+	}
+	log.Printf("已删除 data_date 中 %d 条重复数据", res.RowsAffected)
+	return nil
+}
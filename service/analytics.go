@@ -0,0 +1,199 @@
+package service
+
+import (
+	"math"
+	"time"
+)
+
+// groupByShift 按船舶的班次安排把记录划分到班次，并记下每条记录所在班次开始的日期。
+// 返回的班次名称按班次安排排列，不同班次安排中同名的班次合并统计
+func groupByShift(ship *ShipConfig, loc *time.Location, records []*record) ([]string, map[string][]*record) {
+	groups := make(map[string][]*record)
+	for _, r := range records {
+		slot := ship.shiftAt(time.UnixMilli(r.RecordTime).In(loc))
+		r.ShiftDate = slot.Date
+		groups[slot.Name] = append(groups[slot.Name], r)
+	}
+	return ship.shiftNames(), groups
+}
+
+// recordSpan 返回记录中最早和最晚的时间
+func recordSpan(records []*record) (minTime, maxTime time.Time) {
+	for i, r := range records {
+		t := time.UnixMilli(r.RecordTime)
+		if i == 0 || t.Before(minTime) {
+			minTime = t
+		}
+		if i == 0 || t.After(maxTime) {
+			maxTime = t
+		}
+	}
+	return minTime, maxTime
+}
+
+// workload 一组记录的施工时长、产量和能耗，按实际采样间隔对时间积分，数据中断和未施工的间隔不计入
+type workload struct {
+	Duration            float64   // 累计施工时长（分钟）
+	Production          float64   // 产量：产量率对时间积分（m³）
+	EstimatedProduction float64   // 按流量 × 浓度估算的产量（m³），用于校核产量率
+	Energy              float64   // 能耗：功率对时间积分（kWh）
+	Fuel                float64   // 油耗：油耗率对时间积分（L），未配置油耗率时为 0
+	Gaps                []DataGap // 未计入时长的数据中断
+}
+
+// UnitEnergy 单位产量能耗，产量为 0 时返回 0
+func (w workload) UnitEnergy() float64 {
+	if w.Production > 0 {
+		return w.Energy / w.Production
+	}
+	return 0
+}
+
+// measureWorkload 按梯形法对同一班次内相邻记录的采样间隔积分。
+// 间隔超过阈值视为数据中断，不计入并返回；间隔起点未达到施工条件的也不计入。records 须按时间升序排列
+func measureWorkload(records []*record, loc *time.Location, rule ShipDurationRule) workload {
+	var w workload
+	maxGap := rule.maxGap()
+	eachInterval(records, loc, func(prev, cur *record) {
+		interval := cur.RecordTime - prev.RecordTime
+		if interval > maxGap {
+			w.Gaps = append(w.Gaps, newDataGap(prev.RecordTime, cur.RecordTime))
+			return
+		}
+		if !rule.working(prev) {
+			return
+		}
+		hours := float64(interval) / float64(time.Hour/time.Millisecond)
+		w.Duration += hours * 60
+		w.Production += (prev.OutputRate + cur.OutputRate) / 2 * hours
+		w.EstimatedProduction += (prev.solidsRate() + cur.solidsRate()) / 2 * hours
+		w.Energy += (prev.Power + cur.Power) / 2 * hours
+		w.Fuel += (prev.FuelRate + cur.FuelRate) / 2 * hours
+	})
+	return w
+}
+
+// calParams 统计一组记录（按时间升序）的施工参数，返回参数统计和最大产量率出现的时间
+func calParams(records []*record, cfg ShipHydraulicsConfig) (ParameterStats, int64) {
+	var (
+		horizontalSpeeds   = make([]float64, len(records))
+		carriageTravels    = make([]float64, len(records))
+		cutterDepths       = make([]float64, len(records))
+		spumpRpms          = make([]float64, len(records))
+		concentrations     = make([]float64, len(records))
+		flows              = make([]float64, len(records))
+		dischargePressures = make([]float64, len(records))
+		vacuumDegrees      = make([]float64, len(records))
+		warning            string
+	)
+
+	maxOutputRate := -1.0
+	maxIndex := 0
+
+	for i, r := range records {
+		if r.OutputRate > maxOutputRate {
+			maxOutputRate = r.OutputRate
+			maxIndex = i
+		}
+		carriageTravels[i] = r.TrolleyTravel
+		cutterDepths[i] = -r.CutterDepth
+		spumpRpms[i] = r.PumpSpeed
+		concentrations[i] = r.Concentration
+		flows[i] = r.FlowRate
+		dischargePressures[i] = r.DischargePressure
+		vacuumDegrees[i] = calcVacuumKPa(r, cfg)
+		// 横移速度只统计产量非0但横移速度为0、需要由绞刀位置补算的记录，其余记录按0计入
+		if r.OutputRate > 0 && r.TransverseSpeed == 0 {
+			currentTime := r.RecordTime
+			targetTime := currentTime + calHorizontalSpeedTimeDuration // 3分钟后的时间戳
+			var nextRecord *record
+
+			// 查找 3 分钟后的记录
+			for j := i + 1; j < len(records); j++ {
+				if records[j].RecordTime >= targetTime {
+					nextRecord = records[j]
+					break
+				}
+			}
+
+			// 若无 3 分钟后的记录，使用最后一条记录
+			if nextRecord == nil && len(records) > i+1 {
+				nextRecord = records[len(records)-1]
+			}
+
+			if nextRecord != nil {
+				// 计算两点间距离
+				distance := math.Hypot(nextRecord.CutterX-r.CutterX, nextRecord.CutterY-r.CutterY)
+
+				// 计算时间差（单位：分钟）
+				timeDiff := float64(nextRecord.RecordTime-currentTime) / 1000.0 / 60
+				if timeDiff > 3 {
+					timeDiff = 3 // 限制为 3 分钟
+				}
+
+				// 计算横移速度
+				horizontalSpeeds[i] = distance / timeDiff
+				warning = "横移速度为0，已通过绞刀位置重新计算"
+			} else {
+				warning = "存在产量非0，但是横移速度为0的数据，且无法计算，请检查传感器状态"
+			}
+		}
+	}
+
+	horizontalSpeed := HorizontalSpeed{
+		Parameter: calculateStats(horizontalSpeeds),
+		Warning:   warning,
+	}
+	carriageTravel := calculateStats(carriageTravels)
+	cutterDepth := calculateStats(cutterDepths)
+	sPumpRpm := calculateStats(spumpRpms)
+	concentration := calculateStats(concentrations)
+	flow := calculateStats(flows)
+	dischargePressure := calculateStats(dischargePressures)
+	vacuumDegree := calculateStats(vacuumDegrees)
+
+	horizontalSpeed.MaxProductionParam = round(horizontalSpeeds[maxIndex])
+	carriageTravel.MaxProductionParam = round(carriageTravels[maxIndex])
+	cutterDepth.MaxProductionParam = round(cutterDepths[maxIndex])
+	sPumpRpm.MaxProductionParam = round(spumpRpms[maxIndex])
+	concentration.MaxProductionParam = round(concentrations[maxIndex])
+	flow.MaxProductionParam = round(flows[maxIndex])
+	dischargePressure.MaxProductionParam = round(dischargePressures[maxIndex])
+	vacuumDegree.MaxProductionParam = round(vacuumDegrees[maxIndex])
+
+	// 平均真空度忽略无法估算（NaN/Inf）的记录
+	if avg, ok := averageVacuum(records, cfg); ok {
+		vacuumDegree.Average = round(avg)
+	}
+	if math.IsNaN(vacuumDegree.MaxProductionParam) || math.IsInf(vacuumDegree.MaxProductionParam, 0) {
+		vacuumDegree.MaxProductionParam = 0
+	}
+
+	return ParameterStats{
+		HorizontalSpeed:              horizontalSpeed,
+		CarriageTravel:               carriageTravel,
+		CutterDepth:                  cutterDepth,
+		SPumpRpm:                     sPumpRpm,
+		Concentration:                concentration,
+		Flow:                         flow,
+		BoosterPumpDischargePressure: dischargePressure,
+		VacuumDegree:                 vacuumDegree,
+	}, records[maxIndex].RecordTime
+}
+
+// averageVacuum 统计一组记录的平均估算真空度（kPa）；忽略 NaN/Inf
+func averageVacuum(records []*record, cfg ShipHydraulicsConfig) (avg float64, ok bool) {
+	var sum float64
+	var n int
+	for _, r := range records {
+		v := calcVacuumKPa(r, cfg)
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
@@ -0,0 +1,52 @@
+package service
+
+import "testing"
+
+func TestCalParamsHorizontalSpeed(t *testing.T) {
+	tests := []struct {
+		name        string
+		records     []*record
+		wantMax     float64
+		wantOptimal float64
+		wantWarning string
+	}{
+		{
+			name: "横移速度不为0的记录按0计入",
+			records: []*record{
+				{RecordTime: 0, OutputRate: 100, TransverseSpeed: 7},
+				{RecordTime: 60000, OutputRate: 50, TransverseSpeed: 9},
+			},
+		},
+		{
+			name: "产量非0且横移速度为0时按绞刀位置补算",
+			records: []*record{
+				{RecordTime: 0, OutputRate: 100},
+				{RecordTime: 60000, OutputRate: 50, TransverseSpeed: 7, CutterX: 3, CutterY: 4},
+				{RecordTime: 120000, TransverseSpeed: 9, CutterX: 6, CutterY: 8},
+			},
+			// 无 3 分钟后的记录，使用最后一条：10 m / 2 min
+			wantMax:     5,
+			wantOptimal: 5,
+			wantWarning: "横移速度为0，已通过绞刀位置重新计算",
+		},
+		{
+			name: "最后一条记录无法补算",
+			records: []*record{
+				{RecordTime: 0, OutputRate: 50, TransverseSpeed: 7},
+				{RecordTime: 60000, OutputRate: 100},
+			},
+			wantWarning: "存在产量非0，但是横移速度为0的数据，且无法计算，请检查传感器状态",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats, _ := calParams(tt.records, defaultHydraulicsConfig())
+			got := stats.HorizontalSpeed
+			if got.Max != tt.wantMax || got.MaxProductionParam != tt.wantOptimal || got.Warning != tt.wantWarning {
+				t.Errorf("HorizontalSpeed = %+v, want max %v, maxProductionParam %v, warning %q",
+					got, tt.wantMax, tt.wantOptimal, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"dredger/model"
+	"dredger/pkg/logger"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 班组统计的分组维度
+const (
+	DimensionShift = "shift" // 按班次（时段）合并所有日期
+	DimensionCrew  = "crew"  // 按值班班组
+)
+
+// unassignedCrew 排班表和轮换都没有覆盖的班次
+const unassignedCrew = "未排班"
+
+// 排班来源
+const (
+	crewSourceCalendar = "calendar"
+	crewSourceRotation = "rotation"
+)
+
+// normalizeCrewRotations 按开始日期排列并校验班组轮换，轮换中的班次必须在班次安排中
+func (c *ShipConfig) normalizeCrewRotations() error {
+	shifts := make(map[string]bool)
+	for _, name := range c.shiftNames() {
+		shifts[name] = true
+	}
+	for i := range c.CrewRotations {
+		rotation := &c.CrewRotations[i]
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(rotation.StartDate))
+		if err != nil {
+			return fmt.Errorf("班组轮换的开始日期有误: %q，应为 YYYY-MM-DD", rotation.StartDate)
+		}
+		rotation.StartDate = date.Format(time.DateOnly)
+		if len(rotation.Pattern) == 0 {
+			return fmt.Errorf("%s 起的班组轮换没有排班", rotation.StartDate)
+		}
+		for _, day := range rotation.Pattern {
+			for shift, crew := range day {
+				if !shifts[shift] {
+					return fmt.Errorf("%s 起的班组轮换中的班次 %s 不在班次安排中", rotation.StartDate, shift)
+				}
+				if strings.TrimSpace(crew) == "" {
+					return fmt.Errorf("%s 起的班组轮换中班次 %s 的班组为空", rotation.StartDate, shift)
+				}
+				day[shift] = strings.TrimSpace(crew)
+			}
+		}
+	}
+	sort.SliceStable(c.CrewRotations, func(i, j int) bool {
+		return c.CrewRotations[i].StartDate < c.CrewRotations[j].StartDate
+	})
+	for i := 1; i < len(c.CrewRotations); i++ {
+		if c.CrewRotations[i].StartDate == c.CrewRotations[i-1].StartDate {
+			return fmt.Errorf("班组轮换的开始日期 %s 重复", c.CrewRotations[i].StartDate)
+		}
+	}
+	return nil
+}
+
+// crewRoster 某船一段时间内的排班：排班表中的记录优先，其余按班组轮换推算
+type crewRoster struct {
+	rotations []CrewRotation
+	calendar  map[shiftSlot]string
+}
+
+// loadCrewRoster 加载 [startTime, endTime] 内的排班表，包括前一天开始、跨越午夜的班次
+func loadCrewRoster(db *gorm.DB, ship *ShipConfig, loc *time.Location, startTime, endTime int64) (*crewRoster, error) {
+	roster := &crewRoster{rotations: ship.CrewRotations, calendar: make(map[shiftSlot]string)}
+	var rows []*model.CrewAssignment
+	err := db.Where("ship_name = ?", ship.Name).
+		Where("date BETWEEN ? AND ?",
+			time.UnixMilli(startTime).In(loc).AddDate(0, 0, -1).Format(time.DateOnly),
+			time.UnixMilli(endTime).In(loc).Format(time.DateOnly)).
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		roster.calendar[shiftSlot{Name: row.ShiftName, Date: row.Date}] = row.Crew
+	}
+	return roster, nil
+}
+
+// crewOf 返回某个班次的值班班组和排班来源，未排班时班组为空
+func (r *crewRoster) crewOf(slot shiftSlot) (crew, source string) {
+	if crew, ok := r.calendar[slot]; ok {
+		return crew, crewSourceCalendar
+	}
+	var rotation *CrewRotation
+	for i := range r.rotations {
+		if r.rotations[i].StartDate <= slot.Date {
+			rotation = &r.rotations[i]
+		}
+	}
+	if rotation == nil {
+		return "", ""
+	}
+	start, _ := time.Parse(time.DateOnly, rotation.StartDate)
+	date, err := time.Parse(time.DateOnly, slot.Date)
+	if err != nil {
+		return "", ""
+	}
+	day := int(date.Sub(start).Hours()/24) % len(rotation.Pattern)
+	if crew := rotation.Pattern[day][slot.Name]; crew != "" {
+		return crew, crewSourceRotation
+	}
+	return "", ""
+}
+
+// groupRecords 按维度对记录分组，返回按顺序排列的分组名称和各组记录
+func (s *Service) groupRecords(ship *ShipConfig, loc *time.Location, records []*record, startTime, endTime int64, dimension string) ([]string, map[string][]*record, error) {
+	shifts, byShift := groupByShift(ship, loc, records)
+	if dimension != DimensionCrew {
+		return shifts, byShift, nil
+	}
+
+	roster, err := loadCrewRoster(s.db, ship, loc, startTime, endTime)
+	if err != nil {
+		return nil, nil, fmt.Errorf("加载排班表失败: %v", err)
+	}
+	groups := make(map[string][]*record)
+	for shift, shiftRecords := range byShift {
+		for _, r := range shiftRecords {
+			crew, _ := roster.crewOf(shiftSlot{Name: shift, Date: r.ShiftDate})
+			if crew == "" {
+				crew = unassignedCrew
+			}
+			groups[crew] = append(groups[crew], r)
+		}
+	}
+	crews := make([]string, 0, len(groups))
+	for crew := range groups {
+		crews = append(crews, crew)
+	}
+	sort.Slice(crews, func(i, j int) bool {
+		// 未排班放在最后
+		if (crews[i] == unassignedCrew) != (crews[j] == unassignedCrew) {
+			return crews[j] == unassignedCrew
+		}
+		return crews[i] < crews[j]
+	})
+	return crews, groups, nil
+}
+
+// newShiftWorkParams 按分组维度填写班次名称或班组
+func newShiftWorkParams(key, dimension string, params ParameterStats, optimalTime int64) *ShiftWorkParams {
+	p := &ShiftWorkParams{Parameters: params, OptimalTime: optimalTime}
+	if dimension == DimensionCrew {
+		p.Crew = key
+	} else {
+		p.ShiftName = key
+	}
+	return p
+}
+
+// GetCrewCalendar 返回某船 [startTime, endTime] 内每天每个班次的值班班组
+func (s *Service) GetCrewCalendar(shipName string, startTime, endTime int64) ([]*CrewAssignment, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	loc := ShipLocation(shipName)
+	roster, err := loadCrewRoster(s.db, ship, loc, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]加载排班表失败: %v", shipName, err)
+		return nil, err
+	}
+
+	var list []*CrewAssignment
+	last := time.UnixMilli(endTime).In(loc).Format(time.DateOnly)
+	for day := time.UnixMilli(startTime).In(loc); day.Format(time.DateOnly) <= last; day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		for _, shift := range ship.scheduleOn(date).Shifts {
+			crew, source := roster.crewOf(shiftSlot{Name: shift.Name, Date: date})
+			list = append(list, &CrewAssignment{Date: date, ShiftName: shift.Name, Crew: crew, Source: source})
+		}
+	}
+	return list, nil
+}
+
+// SaveCrewAssignments 保存排班表中的记录，覆盖班组轮换；班组为空时删除该班次的记录，恢复按轮换推算
+func (s *Service) SaveCrewAssignments(shipName string, assignments []*CrewAssignment) error {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return err
+	}
+
+	var upserts []*model.CrewAssignment
+	var deletes []shiftSlot
+	for _, a := range assignments {
+		date, err := time.Parse(time.DateOnly, strings.TrimSpace(a.Date))
+		if err != nil {
+			return fmt.Errorf("排班日期有误: %q，应为 YYYY-MM-DD", a.Date)
+		}
+		slot := shiftSlot{Name: strings.TrimSpace(a.ShiftName), Date: date.Format(time.DateOnly)}
+		found := false
+		for _, shift := range ship.scheduleOn(slot.Date).Shifts {
+			found = found || shift.Name == slot.Name
+		}
+		if !found {
+			return fmt.Errorf("%s 生效的班次安排中没有班次 %q", slot.Date, a.ShiftName)
+		}
+		if crew := strings.TrimSpace(a.Crew); crew != "" {
+			upserts = append(upserts, &model.CrewAssignment{ShipName: shipName, Date: slot.Date, ShiftName: slot.Name, Crew: crew})
+		} else {
+			deletes = append(deletes, slot)
+		}
+	}
+	if len(upserts) == 0 && len(deletes) == 0 {
+		return errors.New("没有需要保存的排班")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, slot := range deletes {
+			err := tx.Where("ship_name = ? AND date = ? AND shift_name = ?", shipName, slot.Date, slot.Name).
+				Delete(&model.CrewAssignment{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(upserts) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "ship_name"}, {Name: "date"}, {Name: "shift_name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"updated_at", "crew"}),
+		}).Create(upserts).Error
+	})
+	if err != nil {
+		logger.Logger.Errorf("[%s]保存排班表失败: %v", shipName, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import "testing"
+
+func TestCrewOf(t *testing.T) {
+	roster := &crewRoster{
+		rotations: []CrewRotation{
+			{StartDate: "2026-03-01", Pattern: []map[string]string{
+				{"白班": "甲", "夜班": "乙"},
+				{"白班": "乙", "夜班": "丙"},
+				{"白班": "丙", "夜班": "甲"},
+			}},
+			// 新的轮换只排白班
+			{StartDate: "2026-03-20", Pattern: []map[string]string{{"白班": "丁"}}},
+		},
+		calendar: map[shiftSlot]string{
+			{Name: "夜班", Date: "2026-03-05"}: "戊",
+		},
+	}
+
+	tests := []struct {
+		name       string
+		slot       shiftSlot
+		wantCrew   string
+		wantSource string
+	}{
+		{name: "轮换第一天", slot: shiftSlot{Name: "白班", Date: "2026-03-01"}, wantCrew: "甲", wantSource: crewSourceRotation},
+		{name: "按天数取模", slot: shiftSlot{Name: "白班", Date: "2026-03-05"}, wantCrew: "乙", wantSource: crewSourceRotation},
+		{name: "跨月", slot: shiftSlot{Name: "夜班", Date: "2026-03-19"}, wantCrew: "乙", wantSource: crewSourceRotation},
+		{name: "排班表优先于轮换", slot: shiftSlot{Name: "夜班", Date: "2026-03-05"}, wantCrew: "戊", wantSource: crewSourceCalendar},
+		{name: "新的轮换生效", slot: shiftSlot{Name: "白班", Date: "2026-03-22"}, wantCrew: "丁", wantSource: crewSourceRotation},
+		{name: "新的轮换中没有该班次", slot: shiftSlot{Name: "夜班", Date: "2026-03-22"}},
+		{name: "轮换开始之前", slot: shiftSlot{Name: "白班", Date: "2026-02-28"}},
+		{name: "日期有误", slot: shiftSlot{Name: "白班", Date: "2026-3-5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			crew, source := roster.crewOf(tt.slot)
+			if crew != tt.wantCrew || source != tt.wantSource {
+				t.Errorf("crewOf(%+v) = %s, %s, want %s, %s", tt.slot, crew, source, tt.wantCrew, tt.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"database/sql"
+	"dredger/model"
+	"dredger/pkg/logger"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dataTable 按船舶登记的数据结构返回存放该船施工数据的模型
+func dataTable(shipName string) (any, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	return ship.dataModel(), nil
+}
+
+// dayStart 返回 ms 在时区 loc 中所在自然日的 0 点，与 data_date.date 的取值一致
+func dayStart(ms int64, loc *time.Location) time.Time {
+	t := time.UnixMilli(ms).In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// RefreshDataDates 按施工数据表中的实际记录重新统计某船 [from, to] 所在各天（按船舶时区划分）的 data_date：
+// 有数据的天写入行数和首末时间，没有数据的天删除。返回有数据的天数和总行数
+func RefreshDataDates(db *gorm.DB, shipName string, from, to int64) (int, int64, error) {
+	table, err := dataTable(shipName)
+	if err != nil {
+		return 0, 0, err
+	}
+	loc := ShipLocation(shipName)
+	start, end := dayStart(from, loc), dayStart(to, loc).AddDate(0, 0, 1)
+
+	var (
+		days    []int64
+		records int64
+	)
+	for cursor := start; cursor.Before(end); {
+		// 直接跳到下一条数据所在的天，中间没有数据的天不逐天查询
+		var next sql.NullInt64
+		err := db.Model(table).
+			Select("MIN(record_time)").
+			Where("ship_name = ? AND record_time >= ? AND record_time < ?", shipName, cursor.UnixMilli(), end.UnixMilli()).
+			Scan(&next).Error
+		if err != nil {
+			return 0, 0, err
+		}
+		if !next.Valid {
+			break
+		}
+
+		day := dayStart(next.Int64, loc)
+		dayEnd := day.AddDate(0, 0, 1)
+		coverage := model.DataDate{ShipName: shipName, Date: day.UnixMilli()}
+		err = db.Model(table).
+			Select("COUNT(*) AS record_count, MIN(record_time) AS first_time, MAX(record_time) AS last_time").
+			Where("ship_name = ? AND record_time >= ? AND record_time < ?", shipName, day.UnixMilli(), dayEnd.UnixMilli()).
+			Scan(&coverage).Error
+		if err != nil {
+			return 0, 0, err
+		}
+		err = db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "ship_name"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"record_count", "first_time", "last_time"}),
+		}).Create(&coverage).Error
+		if err != nil {
+			return 0, 0, err
+		}
+
+		days = append(days, coverage.Date)
+		records += coverage.RecordCount
+		cursor = dayEnd
+	}
+
+	query := db.Where("ship_name = ? AND date >= ? AND date < ?", shipName, start.UnixMilli(), end.UnixMilli())
+	if len(days) > 0 {
+		query = query.Where("date NOT IN ?", days)
+	}
+	if err := query.Delete(&model.DataDate{}).Error; err != nil {
+		return 0, 0, err
+	}
+	return len(days), records, nil
+}
+
+// listDataShips 返回施工数据表或 data_date 中出现过的所有船名
+func listDataShips(db *gorm.DB) ([]string, error) {
+	names := make(map[string]bool)
+	for _, table := range []any{&model.DataDate{}, &model.DredgerDatum{}, &model.DredgerDataHl{}} {
+		var list []string
+		if err := db.Model(table).Distinct().Pluck("ship_name", &list).Error; err != nil {
+			return nil, err
+		}
+		for _, name := range list {
+			names[name] = true
+		}
+	}
+	ships := make([]string, 0, len(names))
+	for name := range names {
+		ships = append(ships, name)
+	}
+	sort.Strings(ships)
+	return ships, nil
+}
+
+// RebuildDataDates 从施工数据表重建 data_date，shipName 为空时重建所有船舶。
+// 施工数据表中已没有数据的船舶，其 data_date 记录全部删除
+func RebuildDataDates(db *gorm.DB, shipName string) (*DataCoverageRebuild, error) {
+	registry.bind(db)
+	ships := []string{shipName}
+	if shipName == "" {
+		var err error
+		if ships, err = listDataShips(db); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &DataCoverageRebuild{Ships: len(ships)}
+	for _, ship := range ships {
+		var bounds struct {
+			First sql.NullInt64
+			Last  sql.NullInt64
+		}
+		// 未登记的船舶不会有施工数据，只清理 data_date
+		table, err := dataTable(ship)
+		if err == nil {
+			err = db.Model(table).
+				Select("MIN(record_time) AS first, MAX(record_time) AS last").
+				Where("ship_name = ?", ship).
+				Scan(&bounds).Error
+			if err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, ErrShipNotRegistered) {
+			return nil, err
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			query := tx.Where("ship_name = ?", ship)
+			if bounds.First.Valid {
+				loc := ShipLocation(ship)
+				query = query.Where("date < ? OR date > ?", dayStart(bounds.First.Int64, loc).UnixMilli(), dayStart(bounds.Last.Int64, loc).UnixMilli())
+			}
+			if err := query.Delete(&model.DataDate{}).Error; err != nil {
+				return err
+			}
+			if !bounds.First.Valid {
+				return nil
+			}
+			days, records, err := RefreshDataDates(tx, ship, bounds.First.Int64, bounds.Last.Int64)
+			result.Days += days
+			result.Records += records
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("重建 %s 的 data_date 失败: %v", ship, err)
+		}
+	}
+	return result, nil
+}
+
+// RebuildDataDates 从施工数据表重建 data_date，shipName 为空时重建所有船舶
+func (s *Service) RebuildDataDates(shipName string) (*DataCoverageRebuild, error) {
+	result, err := RebuildDataDates(s.db, shipName)
+	if err != nil {
+		logger.Logger.Errorf("%v", err)
+		return nil, err
+	}
+	logger.Logger.Infof("已重建 data_date：%d 艘船，%d 天，%d 行数据", result.Ships, result.Days, result.Records)
+	return result, nil
+}
+
+// GetDataCoverage 返回某船 [startDate, endDate] 内每天的数据行数和首末时间
+func (s *Service) GetDataCoverage(shipName string, startDate, endDate int64) ([]*DataCoverage, error) {
+	var records []*model.DataDate
+	err := s.db.Where("ship_name = ? AND date BETWEEN ? AND ?", shipName, startDate, endDate).
+		Order("date ASC").
+		Find(&records).Error
+	if err != nil {
+		logger.Logger.Errorf("查询数据覆盖情况失败: %v", err)
+		return nil, err
+	}
+
+	loc := ShipLocation(shipName)
+	coverage := make([]*DataCoverage, 0, len(records))
+	for _, r := range records {
+		coverage = append(coverage, &DataCoverage{
+			Date:        r.Date,
+			DateStr:     time.UnixMilli(r.Date).In(loc).Format(time.DateOnly),
+			RecordCount: r.RecordCount,
+			FirstTime:   r.FirstTime,
+			LastTime:    r.LastTime,
+		})
+	}
+	return coverage, nil
+}
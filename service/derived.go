@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"dredger/pkg/logger"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// RecomputeDerivedData 按施工数据重新计算 [startTime, endTime] 内由原始数据派生并保存的数据，
+// 用于直接修改数据库或导入逻辑变化之后修正派生数据。shipName 为空时处理所有船舶。
+// 能耗、运行状态等统计都在查询时计算，目前保存下来的派生数据只有 data_date 覆盖统计，
+// 因此等同于限定时间范围的 RebuildDataDates；以后新增保存的派生数据时在这里一并重新计算
+func (s *Service) RecomputeDerivedData(ctx context.Context, shipName string, startTime, endTime int64) (*DataCoverageRebuild, error) {
+	if startTime > endTime {
+		return nil, errors.New("开始时间晚于结束时间")
+	}
+	ships := []string{shipName}
+	if shipName == "" {
+		var err error
+		if ships, err = listDataShips(s.db); err != nil {
+			logger.Logger.Errorf("查询船舶列表失败: %v", err)
+			return nil, err
+		}
+	}
+
+	result := &DataCoverageRebuild{Ships: len(ships)}
+	for _, ship := range ships {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		var (
+			days    int
+			records int64
+		)
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			var err error
+			days, records, err = RefreshDataDates(tx, ship, startTime, endTime)
+			return err
+		})
+		if err != nil {
+			logger.Logger.Errorf("重新统计 %s 的 data_date 失败: %v", ship, err)
+			return result, err
+		}
+		result.Days += days
+		result.Records += records
+	}
+
+	logger.Logger.Infof("已重新计算派生数据：%d 艘船，%d 天，%d 行数据", result.Ships, result.Days, result.Records)
+	return result, nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// 能耗模型
+const (
+	EnergyModelHydraulic  = "hydraulic"  // 按各级泵的流量和压差计算水力功率，再按效率折算为轴功率
+	EnergyModelElectrical = "electrical" // 各电机功率列之和
+	EnergyModelDiesel     = "diesel"     // 各柴油机按负荷查功率和油耗曲线
+)
+
+// 能耗的单位
+const (
+	EnergyUnitKWh      = "kWh"      // 功率为 kW，能耗为 kWh，单位产量能耗为 kWh/m³
+	EnergyUnitRelative = "relative" // 旧版本经验公式的相对值，没有物理单位，不能与其它船比较
+)
+
+// defaultHydraulicCoefficient 旧版本估算 ml 数据功率的经验系数：功率 = 0.8 × 流量 × 压差
+const defaultHydraulicCoefficient = 0.8
+
+// defaultEnergyModel 各数据结构默认的能耗模型：
+// hl 记录了各泵的电机功率，直接求和；ml 只有压力，按水下泵（吸入真空 → 中间压力）和升压泵（中间压力 → 排出压力）两级估算，
+// 沿用旧版本的经验公式，不做单位换算，能耗以 EnergyUnitRelative 报告
+func defaultEnergyModel(schema string) ShipEnergyModel {
+	if schema == DataSchemaHL {
+		return ShipEnergyModel{
+			Type:         EnergyModelElectrical,
+			PowerColumns: []string{"underwater_pump_power", "mud_pump_1_power", "mud_pump_2_power"},
+		}
+	}
+	return ShipEnergyModel{
+		Type:       EnergyModelHydraulic,
+		FlowColumn: "flow_rate",
+		Stages: []EnergyStage{
+			{Name: "水下泵", InletColumn: "underwater_pump_suction_vacuum", OutletColumn: "intermediate_pressure"},
+			{Name: "升压泵", InletColumn: "intermediate_pressure", OutletColumn: "booster_pump_discharge_pressure"},
+		},
+		Coefficient: defaultHydraulicCoefficient,
+	}
+}
+
+// normalize 补全默认值并校验能耗模型，未配置时使用数据结构默认的模型
+func (m *ShipEnergyModel) normalize(schema string) error {
+	if m.Type == "" {
+		*m = defaultEnergyModel(schema)
+	}
+	if m.Efficiency == 0 {
+		m.Efficiency = 1
+	}
+	if m.Efficiency < 0 || m.Efficiency > 1 {
+		return fmt.Errorf("能耗模型的效率系数 %v 应在 (0, 1] 之间", m.Efficiency)
+	}
+	if m.SpecificFuel < 0 {
+		return errors.New("能耗模型的单位油耗不能为负数")
+	}
+
+	switch m.Type {
+	case EnergyModelHydraulic:
+		if len(m.Stages) == 0 {
+			return errors.New("水力能耗模型至少需要一级泵")
+		}
+		if m.FlowColumn == "" {
+			m.FlowColumn = "flow_rate"
+		}
+		if m.Coefficient == 0 {
+			m.Coefficient = defaultHydraulicCoefficient
+		}
+		if m.Coefficient < 0 {
+			return errors.New("水力能耗模型的系数不能为负数")
+		}
+		if m.PowerInKW && m.Coefficient == defaultHydraulicCoefficient {
+			return errors.New("水力能耗模型按 kW 计算时须配置按物理单位换算的系数，如 1000 / 3600 / η")
+		}
+	case EnergyModelElectrical:
+		if len(m.PowerColumns) == 0 {
+			return errors.New("电力能耗模型至少需要一个功率列")
+		}
+	case EnergyModelDiesel:
+		if len(m.Engines) == 0 {
+			return errors.New("柴油机能耗模型至少需要一台柴油机")
+		}
+		for i := range m.Engines {
+			engine := &m.Engines[i]
+			if len(engine.Curve) < 2 {
+				return fmt.Errorf("柴油机 %s 的负荷曲线至少需要两个点", engine.Name)
+			}
+			sort.Slice(engine.Curve, func(a, b int) bool { return engine.Curve[a].Load < engine.Curve[b].Load })
+		}
+	default:
+		return fmt.Errorf("不支持的能耗模型 %q，应为 %s、%s 或 %s", m.Type, EnergyModelHydraulic, EnergyModelElectrical, EnergyModelDiesel)
+	}
+	return nil
+}
+
+// energyUnit 返回能耗的单位：电力和柴油机模型为 kWh，水力模型只有明确按物理单位换算时才是 kWh
+func (m ShipEnergyModel) energyUnit() string {
+	if m.Type == EnergyModelHydraulic && !m.PowerInKW {
+		return EnergyUnitRelative
+	}
+	return EnergyUnitKWh
+}
+
+// energyKWh 单位为 kWh 时返回能耗，否则返回 nil，避免把相对值当作 kWh 报告
+func energyKWh(energy float64, unit string) *float64 {
+	if unit != EnergyUnitKWh {
+		return nil
+	}
+	v := round(energy)
+	return &v
+}
+
+// energyView 已解析为模型字段下标的能耗模型
+type energyView struct {
+	ShipEnergyModel
+	flow    int
+	stages  [][2]int // 进口、出口压力
+	power   []int
+	engines []int // 各柴油机的负荷列
+}
+
+// newEnergyView 解析能耗模型用到的列，返回需要额外查询的列
+func newEnergyView(modelType reflect.Type, m ShipEnergyModel, available map[string]int) (*energyView, []string, error) {
+	view := &energyView{ShipEnergyModel: m}
+	var columns []string
+	field := func(column string) (int, error) {
+		index, ok := available[column]
+		if !ok {
+			return 0, fmt.Errorf("能耗模型中的列 %s 不是 %s 的数值列", column, modelType.Name())
+		}
+		columns = append(columns, column)
+		return index, nil
+	}
+
+	var err error
+	switch m.Type {
+	case EnergyModelHydraulic:
+		if view.flow, err = field(m.FlowColumn); err != nil {
+			return nil, nil, err
+		}
+		for _, stage := range m.Stages {
+			inlet, err := field(stage.InletColumn)
+			if err != nil {
+				return nil, nil, err
+			}
+			outlet, err := field(stage.OutletColumn)
+			if err != nil {
+				return nil, nil, err
+			}
+			view.stages = append(view.stages, [2]int{inlet, outlet})
+		}
+	case EnergyModelElectrical:
+		for _, column := range m.PowerColumns {
+			index, err := field(column)
+			if err != nil {
+				return nil, nil, err
+			}
+			view.power = append(view.power, index)
+		}
+	case EnergyModelDiesel:
+		for _, engine := range m.Engines {
+			index, err := field(engine.LoadColumn)
+			if err != nil {
+				return nil, nil, err
+			}
+			view.engines = append(view.engines, index)
+		}
+	}
+	return view, columns, nil
+}
+
+// apply 计算一条记录的功率（kW）和油耗率（L/h）
+func (v *energyView) apply(src reflect.Value, r *record) {
+	var power, fuel float64
+	switch v.Type {
+	case EnergyModelHydraulic:
+		flow := src.Field(v.flow).Float()
+		for _, stage := range v.stages {
+			diff := src.Field(stage[1]).Float() - src.Field(stage[0]).Float()
+			power += v.Coefficient * flow * diff
+		}
+	case EnergyModelElectrical:
+		for _, i := range v.power {
+			power += src.Field(i).Float()
+		}
+	case EnergyModelDiesel:
+		for i, engine := range v.Engines {
+			p, f := engine.at(src.Field(v.engines[i]).Float())
+			power += p
+			fuel += f
+		}
+	}
+	r.Power = power / v.Efficiency
+	if v.Type != EnergyModelDiesel {
+		fuel = r.Power * v.SpecificFuel
+	}
+	r.FuelRate = fuel
+}
+
+// at 按负荷在曲线上线性插值，超出曲线范围时取端点的值
+func (e DieselEngine) at(load float64) (power, fuel float64) {
+	curve := e.Curve
+	if load <= curve[0].Load {
+		return curve[0].Power, curve[0].FuelRate
+	}
+	for i := 1; i < len(curve); i++ {
+		if load <= curve[i].Load {
+			a, b := curve[i-1], curve[i]
+			k := (load - a.Load) / (b.Load - a.Load)
+			return a.Power + k*(b.Power-a.Power), a.FuelRate + k*(b.FuelRate-a.FuelRate)
+		}
+	}
+	last := curve[len(curve)-1]
+	return last.Power, last.FuelRate
+}
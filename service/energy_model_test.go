@@ -0,0 +1,198 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"dredger/model"
+)
+
+var testDieselCurve = []DieselCurvePoint{
+	{Load: 20, Power: 400, FuelRate: 90},
+	{Load: 50, Power: 1000, FuelRate: 210},
+	{Load: 100, Power: 2000, FuelRate: 420},
+}
+
+func TestDieselEngineAt(t *testing.T) {
+	engine := DieselEngine{Name: "1#", Curve: testDieselCurve}
+
+	tests := []struct {
+		name      string
+		load      float64
+		wantPower float64
+		wantFuel  float64
+	}{
+		{name: "低于曲线取起点", load: 0, wantPower: 400, wantFuel: 90},
+		{name: "起点", load: 20, wantPower: 400, wantFuel: 90},
+		{name: "区间内插值", load: 35, wantPower: 700, wantFuel: 150},
+		{name: "中间点", load: 50, wantPower: 1000, wantFuel: 210},
+		{name: "终点", load: 100, wantPower: 2000, wantFuel: 420},
+		{name: "高于曲线取终点", load: 120, wantPower: 2000, wantFuel: 420},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			power, fuel := engine.at(tt.load)
+			if math.Abs(power-tt.wantPower) > 1e-9 || math.Abs(fuel-tt.wantFuel) > 1e-9 {
+				t.Errorf("at(%v) = (%v, %v), want (%v, %v)", tt.load, power, fuel, tt.wantPower, tt.wantFuel)
+			}
+		})
+	}
+}
+
+func TestEnergyViewApply(t *testing.T) {
+	ml := model.DredgerDatum{
+		FlowRate:                     1000,
+		UnderwaterPumpSuctionVacuum:  -0.05,
+		IntermediatePressure:         0.6,
+		BoosterPumpDischargePressure: 1.8,
+	}
+	hl := model.DredgerDataHl{
+		UnderwaterPumpPower: 300,
+		MudPump1Power:       500,
+		MudPump2Power:       700,
+		MudPump1DieselLoad:  35,
+		MudPump2DieselLoad:  120,
+	}
+
+	tests := []struct {
+		name      string
+		schema    string
+		energy    ShipEnergyModel
+		src       any
+		wantPower float64
+		wantFuel  float64
+	}{
+		{
+			name:   "ml 默认沿用旧版本的 0.8 × 流量 × 压差",
+			schema: DataSchemaML,
+			src:    ml,
+			// 与旧版本 pw1 + pw2 相同
+			wantPower: 0.8*1000*(0.6-(-0.05)) + 0.8*1000*(1.8-0.6),
+		},
+		{
+			name:   "明确配置的系数和单位油耗",
+			schema: DataSchemaML,
+			energy: ShipEnergyModel{
+				Type:         EnergyModelHydraulic,
+				Stages:       []EnergyStage{{InletColumn: "intermediate_pressure", OutletColumn: "booster_pump_discharge_pressure"}},
+				Coefficient:  1000.0 / 3600,
+				Efficiency:   0.8,
+				SpecificFuel: 0.2,
+			},
+			src:       ml,
+			wantPower: 1000.0 / 3600 * 1000 * 1.2 / 0.8,
+			wantFuel:  1000.0 / 3600 * 1000 * 1.2 / 0.8 * 0.2,
+		},
+		{
+			name:      "hl 默认对各泵电机功率求和",
+			schema:    DataSchemaHL,
+			src:       hl,
+			wantPower: 1500,
+		},
+		{
+			name:   "柴油机按负荷曲线插值",
+			schema: DataSchemaHL,
+			energy: ShipEnergyModel{
+				Type: EnergyModelDiesel,
+				Engines: []DieselEngine{
+					{Name: "1#", LoadColumn: "mud_pump_1_diesel_load", Curve: testDieselCurve},
+					{Name: "2#", LoadColumn: "mud_pump_2_diesel_load", Curve: testDieselCurve},
+				},
+			},
+			src:       hl,
+			wantPower: 700 + 2000,
+			wantFuel:  150 + 420,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			energy := tt.energy
+			if err := energy.normalize(tt.schema); err != nil {
+				t.Fatal(err)
+			}
+			modelType := reflect.TypeOf(tt.src)
+			view, _, err := newEnergyView(modelType, energy, floatColumns(modelType))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var r record
+			view.apply(reflect.ValueOf(tt.src), &r)
+			if math.Abs(r.Power-tt.wantPower) > 1e-9 || math.Abs(r.FuelRate-tt.wantFuel) > 1e-9 {
+				t.Errorf("apply() = (%v, %v), want (%v, %v)", r.Power, r.FuelRate, tt.wantPower, tt.wantFuel)
+			}
+		})
+	}
+}
+
+func TestEnergyModelNormalizeInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		energy ShipEnergyModel
+	}{
+		{name: "效率超过 1", energy: ShipEnergyModel{Type: EnergyModelElectrical, PowerColumns: []string{"mud_pump_1_power"}, Efficiency: 1.2}},
+		{name: "负的系数", energy: ShipEnergyModel{Type: EnergyModelHydraulic, Stages: []EnergyStage{{}}, Coefficient: -1}},
+		{name: "按 kW 计算但未配置系数", energy: ShipEnergyModel{Type: EnergyModelHydraulic, Stages: []EnergyStage{{}}, PowerInKW: true}},
+		{name: "没有功率列", energy: ShipEnergyModel{Type: EnergyModelElectrical}},
+		{name: "负荷曲线只有一个点", energy: ShipEnergyModel{Type: EnergyModelDiesel, Engines: []DieselEngine{{Curve: testDieselCurve[:1]}}}},
+		{name: "未知的模型", energy: ShipEnergyModel{Type: "steam"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.energy.normalize(DataSchemaHL); err == nil {
+				t.Error("normalize() 应返回错误")
+			}
+		})
+	}
+}
+
+func TestEnergyUnit(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		energy  ShipEnergyModel
+		want    string
+		wantKWh bool
+	}{
+		{name: "ml 默认的经验公式", schema: DataSchemaML, want: EnergyUnitRelative},
+		{name: "hl 默认的电机功率", schema: DataSchemaHL, want: EnergyUnitKWh, wantKWh: true},
+		{
+			name:   "按物理单位换算的水力模型",
+			schema: DataSchemaML,
+			energy: ShipEnergyModel{
+				Type:        EnergyModelHydraulic,
+				Stages:      []EnergyStage{{InletColumn: "intermediate_pressure", OutletColumn: "booster_pump_discharge_pressure"}},
+				Coefficient: 1000.0 / 3600 / 0.8,
+				PowerInKW:   true,
+			},
+			want:    EnergyUnitKWh,
+			wantKWh: true,
+		},
+		{
+			name:    "柴油机",
+			schema:  DataSchemaHL,
+			energy:  ShipEnergyModel{Type: EnergyModelDiesel, Engines: []DieselEngine{{LoadColumn: "mud_pump_1_diesel_load", Curve: testDieselCurve}}},
+			want:    EnergyUnitKWh,
+			wantKWh: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			energy := tt.energy
+			if err := energy.normalize(tt.schema); err != nil {
+				t.Fatal(err)
+			}
+			unit := energy.energyUnit()
+			if unit != tt.want {
+				t.Errorf("energyUnit() = %s, want %s", unit, tt.want)
+			}
+			if got := energyKWh(12.345, unit); (got != nil) != tt.wantKWh || (got != nil && *got != 12.35) {
+				t.Errorf("energyKWh() = %v, want kWh %v", got, tt.wantKWh)
+			}
+		})
+	}
+}
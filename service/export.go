@@ -0,0 +1,456 @@
+package service
+
+import (
+	"context"
+	"dredger/model"
+	"dredger/pkg/logger"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+	"gorm.io/gorm"
+)
+
+// 导出时每次从数据库读取的行数
+const exportPageSize = 2000
+
+// 导出文件名中的时间格式，与导入文件的命名约定一致
+const exportFileTimeLayout = "2006-01-02-15-04-05"
+
+// CSV 和 XLSX 中 record_time 的格式，整秒时不带小数，否则保留毫秒，导入时按原值解析
+const exportTimeLayout = "2006-01-02 15:04:05.999"
+
+// CSV 导出编码
+const (
+	CSVEncodingUTF8 = "utf8"
+	CSVEncodingGBK  = "gbk"
+)
+
+// 每个工作表最多写入的行数（含表头），超过后换到新的工作表
+var xlsxSheetRows = excelize.TotalRows
+
+// comment 末尾括号中的内容，例如 "流量(m3/h)" 中的 m3/h
+var (
+	commentUnitRe = regexp.MustCompile(`[（(]([^（()）]+)[)）]\s*$`)
+	hanRe         = regexp.MustCompile(`\p{Han}`)
+)
+
+var (
+	columnUnitsOnce sync.Once
+	columnUnits     map[string]string // 数据库列名 -> 单位
+)
+
+// commentUnit 取 comment 末尾括号中的单位，括号内含汉字的视为说明而不是单位，"(null)" 表示没有单位
+func commentUnit(comment string) string {
+	m := commentUnitRe.FindStringSubmatch(comment)
+	if m == nil || m[1] == "null" || hanRe.MatchString(m[1]) {
+		return ""
+	}
+	return m[1]
+}
+
+// columnUnit 返回列的单位。敏龙等船的 comment 不带单位，同名列沿用华安龙模型 comment 中的单位
+func columnUnit(column string) string {
+	columnUnitsOnce.Do(func() {
+		columnUnits = make(map[string]string)
+		for _, t := range []reflect.Type{reflect.TypeOf(model.DredgerDataHl{}), reflect.TypeOf(model.DredgerDatum{})} {
+			for _, c := range importableColumns(t) {
+				if u := commentUnit(c.comment); u != "" && columnUnits[c.column] == "" {
+					columnUnits[c.column] = u
+				}
+			}
+		}
+	})
+	return columnUnits[column]
+}
+
+// exportHeader 中文名加单位，例如 "流量(m3/h)"；导入时去掉单位后仍能匹配到同一列
+func exportHeader(c modelColumn) string {
+	name := c.comment
+	if name == "" {
+		return c.column
+	}
+	unit := columnUnit(c.column)
+	if unit == "" || strings.HasSuffix(name, ")") || strings.HasSuffix(name, "）") {
+		return name
+	}
+	// 去掉 comment 末尾不完整的括号再加单位，例如 "GPS1航速(" 导出为 "GPS1航速(kn)"
+	return fmt.Sprintf("%s(%s)", strings.TrimRight(name, "(（ "), unit)
+}
+
+// rowWriter 将导出的数据逐行写成某种文件格式，values 与构造时的列一一对应，
+// record_time 列的值为 time.Time
+type rowWriter interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// exportColumns 返回要导出的列，record_time 始终在第一列；requested 为空时导出全部列
+func exportColumns(modelType reflect.Type, requested []string) ([]modelColumn, error) {
+	all := importableColumns(modelType)
+	columns := make([]modelColumn, 0, len(all))
+	byName := make(map[string]modelColumn, len(all))
+	for _, c := range all {
+		if c.column == "record_time" {
+			columns = append(columns, c)
+			continue
+		}
+		byName[c.column] = c
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("%s 没有 record_time 列", modelType.Name())
+	}
+
+	if len(requested) == 0 {
+		for _, c := range all {
+			if c.column != "record_time" {
+				columns = append(columns, c)
+			}
+		}
+		return columns, nil
+	}
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if name == "" || name == "record_time" {
+			continue
+		}
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("未知的列: %s", name)
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// ExportFileName 按导入文件的命名约定生成导出文件名，导出的文件可以直接重新导入
+func (s *Service) ExportFileName(opts ExportOptions) string {
+	loc := ShipLocation(opts.ShipName)
+	return fmt.Sprintf("%s%s至%s.%s", opts.ShipName,
+		time.UnixMilli(opts.StartTime).In(loc).Format(exportFileTimeLayout),
+		time.UnixMilli(opts.EndTime).In(loc).Format(exportFileTimeLayout),
+		opts.Format)
+}
+
+// ExportData 将某船一段时间内的施工数据按 opts.Format 写入 w。
+// 按 record_time 分页读取，内存占用与导出的总行数无关；参数有误时在写入任何内容之前返回错误
+func (s *Service) ExportData(ctx context.Context, w io.Writer, opts ExportOptions) error {
+	ship, err := lookupShip(opts.ShipName)
+	if err != nil {
+		return err
+	}
+	modelType := ship.dataModelType()
+
+	columns, err := exportColumns(modelType, opts.Columns)
+	if err != nil {
+		return err
+	}
+	writer, err := newRowWriter(w, opts, columns, modelType)
+	if err != nil {
+		return err
+	}
+
+	// 时间按船舶时区写出，与导入时的解析方式一致
+	loc := ShipLocation(opts.ShipName)
+	values := make([]any, len(columns))
+	emit := func(record reflect.Value) error {
+		exportValues(columns, record, loc, values)
+		return writer.WriteRow(values)
+	}
+
+	var count int
+	switch ship.DataSchema {
+	case DataSchemaHL:
+		count, err = exportRecords[model.DredgerDataHl](ctx, s.db, opts, emit)
+	default:
+		count, err = exportRecords[model.DredgerDatum](ctx, s.db, opts, emit)
+	}
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		logger.Logger.Errorf("导出 %s 的数据失败: %v", opts.ShipName, err)
+		return err
+	}
+
+	logger.Logger.Infof("已导出 %s 的 %d 行数据（%s）", opts.ShipName, count, opts.Format)
+	return nil
+}
+
+// exportValues 将一行数据按导出列取值写入 values，record_time 转为 loc 时区的 time.Time
+func exportValues(columns []modelColumn, record reflect.Value, loc *time.Location, values []any) {
+	for i, c := range columns {
+		field := record.Field(c.field)
+		if c.column == "record_time" {
+			values[i] = time.UnixMilli(field.Int()).In(loc)
+			continue
+		}
+		values[i] = field.Interface()
+	}
+}
+
+// exportRecords 以 record_time 为游标分页读取数据，(ship_name, record_time) 唯一，翻页不会遗漏或重复
+func exportRecords[T any](ctx context.Context, db *gorm.DB, opts ExportOptions, emit func(record reflect.Value) error) (int, error) {
+	var count int
+	last := opts.StartTime - 1
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		var page []*T
+		err := db.Where("ship_name = ? AND record_time > ? AND record_time <= ?", opts.ShipName, last, opts.EndTime).
+			Order("record_time ASC").
+			Limit(exportPageSize).
+			Find(&page).Error
+		if err != nil {
+			return count, err
+		}
+		for _, r := range page {
+			if err = emit(reflect.ValueOf(r).Elem()); err != nil {
+				return count, err
+			}
+		}
+		count += len(page)
+		if len(page) < exportPageSize {
+			return count, nil
+		}
+		last = reflect.ValueOf(page[len(page)-1]).Elem().FieldByName("RecordTime").Int()
+	}
+}
+
+func newRowWriter(w io.Writer, opts ExportOptions, columns []modelColumn, modelType reflect.Type) (rowWriter, error) {
+	switch opts.Format {
+	case FileFormatXLSX:
+		return newXLSXRowWriter(w, columns)
+	case FileFormatCSV:
+		return newCSVRowWriter(w, opts.Encoding, columns)
+	case FileFormatParquet:
+		return newParquetRowWriter(w, columns, modelType)
+	}
+	return nil, fmt.Errorf("不支持的导出格式: %s", opts.Format)
+}
+
+// csvRowWriter 表头为数据库列名，默认 UTF-8 带 BOM（Excel 可直接打开），也可选 GBK
+type csvRowWriter struct {
+	writer *csv.Writer
+	closer io.Closer
+	record []string
+}
+
+func newCSVRowWriter(w io.Writer, encoding string, columns []modelColumn) (*csvRowWriter, error) {
+	cw := &csvRowWriter{record: make([]string, len(columns))}
+	switch encoding {
+	case "", CSVEncodingUTF8:
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, err
+		}
+	case CSVEncodingGBK:
+		tw := transform.NewWriter(w, simplifiedchinese.GB18030.NewEncoder())
+		w, cw.closer = tw, tw
+	default:
+		return nil, fmt.Errorf("不支持的 CSV 编码: %s", encoding)
+	}
+	cw.writer = csv.NewWriter(w)
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.column
+	}
+	if err := cw.writer.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (w *csvRowWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		switch v := v.(type) {
+		case time.Time:
+			w.record[i] = v.Format(exportTimeLayout)
+		case float64:
+			w.record[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			w.record[i] = fmt.Sprint(v)
+		}
+	}
+	return w.writer.Write(w.record)
+}
+
+func (w *csvRowWriter) Close() error {
+	w.writer.Flush()
+	if err := w.writer.Error(); err != nil {
+		return err
+	}
+	if w.closer != nil {
+		return w.closer.Close()
+	}
+	return nil
+}
+
+// parquetRowWriter 列名为数据库列名，record_time 写为毫秒精度的时间戳类型
+type parquetRowWriter struct {
+	writer  *parquet.Writer
+	indexes []int // values 下标 -> parquet 列下标（parquet 按列名排序）
+	rows    []parquet.Row
+}
+
+func newParquetRowWriter(w io.Writer, columns []modelColumn, modelType reflect.Type) (*parquetRowWriter, error) {
+	group := make(parquet.Group, len(columns))
+	for _, c := range columns {
+		if c.column == "record_time" {
+			group[c.column] = parquet.Timestamp(parquet.Millisecond)
+			continue
+		}
+		switch kind := modelType.Field(c.field).Type.Kind(); kind {
+		case reflect.Float64:
+			group[c.column] = parquet.Leaf(parquet.DoubleType)
+		case reflect.Float32:
+			group[c.column] = parquet.Leaf(parquet.FloatType)
+		case reflect.Int32:
+			group[c.column] = parquet.Int(32)
+		case reflect.Int64:
+			group[c.column] = parquet.Int(64)
+		case reflect.String:
+			group[c.column] = parquet.String()
+		default:
+			return nil, fmt.Errorf("列 %s 的类型 %s 不支持导出为 Parquet", c.column, kind)
+		}
+	}
+	schema := parquet.NewSchema(modelType.Name(), group)
+
+	pw := &parquetRowWriter{
+		writer:  parquet.NewWriter(w, schema, parquet.Compression(&parquet.Snappy)),
+		indexes: make([]int, len(columns)),
+	}
+	for i, c := range columns {
+		leaf, _ := schema.Lookup(c.column)
+		pw.indexes[i] = leaf.ColumnIndex
+	}
+	return pw, nil
+}
+
+func (w *parquetRowWriter) WriteRow(values []any) error {
+	row := make(parquet.Row, len(values))
+	for i, v := range values {
+		if t, ok := v.(time.Time); ok {
+			v = t.UnixMilli()
+		}
+		row[w.indexes[i]] = parquet.ValueOf(v).Level(0, 0, w.indexes[i])
+	}
+	w.rows = append(w.rows, row)
+	if len(w.rows) >= exportPageSize {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *parquetRowWriter) flush() error {
+	_, err := w.writer.WriteRows(w.rows)
+	w.rows = w.rows[:0]
+	return err
+}
+
+func (w *parquetRowWriter) Close() error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.writer.Close()
+}
+
+// xlsxRowWriter 表头为中文名和单位，record_time 写为本地时间字符串；
+// 超过单个工作表的行数上限时写到下一个表头相同的工作表，导入时会依次读取
+type xlsxRowWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	sw     *excelize.StreamWriter
+	header []any
+	sheets int // 已创建的工作表数
+	rows   int // 当前工作表已写入的行数（含表头）
+	cells  []any
+}
+
+func newXLSXRowWriter(w io.Writer, columns []modelColumn) (*xlsxRowWriter, error) {
+	xw := &xlsxRowWriter{
+		w:      w,
+		file:   excelize.NewFile(),
+		header: make([]any, len(columns)),
+		cells:  make([]any, len(columns)),
+	}
+	for i, c := range columns {
+		xw.header[i] = exportHeader(c)
+	}
+	if err := xw.nextSheet(); err != nil {
+		xw.file.Close()
+		return nil, err
+	}
+	return xw, nil
+}
+
+func (w *xlsxRowWriter) nextSheet() error {
+	if w.sw != nil {
+		if err := w.sw.Flush(); err != nil {
+			return err
+		}
+	}
+	w.sheets++
+	name := fmt.Sprintf("数据%d", w.sheets)
+	if w.sheets == 1 {
+		if err := w.file.SetSheetName(w.file.GetSheetName(0), name); err != nil {
+			return err
+		}
+	} else if _, err := w.file.NewSheet(name); err != nil {
+		return err
+	}
+
+	sw, err := w.file.NewStreamWriter(name)
+	if err != nil {
+		return err
+	}
+	w.sw = sw
+	if err = sw.SetColWidth(1, 1, 20); err != nil {
+		return err
+	}
+	if err = sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return err
+	}
+	w.rows = 1
+	return sw.SetRow("A1", w.header)
+}
+
+func (w *xlsxRowWriter) WriteRow(values []any) error {
+	if w.rows >= xlsxSheetRows {
+		if err := w.nextSheet(); err != nil {
+			return err
+		}
+	}
+	for i, v := range values {
+		if t, ok := v.(time.Time); ok {
+			v = t.Format(exportTimeLayout)
+		}
+		w.cells[i] = v
+	}
+	w.rows++
+	cell, err := excelize.CoordinatesToCellName(1, w.rows)
+	if err != nil {
+		return err
+	}
+	return w.sw.SetRow(cell, w.cells)
+}
+
+func (w *xlsxRowWriter) Close() error {
+	defer w.file.Close()
+	if err := w.sw.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.w)
+}
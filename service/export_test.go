@@ -0,0 +1,113 @@
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"dredger/model"
+)
+
+// 导出的文件不经修改即可重新导入，且每一列的值不变
+func TestExportRoundTrip(t *testing.T) {
+	useImportConf(t)
+	// 每个工作表只写表头和 2 行，导出的 XLSX 拆成多个工作表
+	defer func(rows int) { xlsxSheetRows = rows }(xlsxSheetRows)
+	xlsxSheetRows = 3
+
+	loc := time.FixedZone("CST", 8*3600)
+	start := time.Date(2026, 3, 10, 8, 0, 0, 0, loc).UnixMilli()
+	tests := []struct {
+		name      string
+		modelType reflect.Type
+		opts      ExportOptions
+		fileName  string
+	}{
+		{name: "华安龙 XLSX", modelType: reflect.TypeOf(model.DredgerDataHl{}), opts: ExportOptions{Format: FileFormatXLSX}, fileName: "a.xlsx"},
+		{name: "敏龙 XLSX", modelType: reflect.TypeOf(model.DredgerDatum{}), opts: ExportOptions{Format: FileFormatXLSX}, fileName: "a.xlsx"},
+		{name: "CSV UTF-8", modelType: reflect.TypeOf(model.DredgerDataHl{}), opts: ExportOptions{Format: FileFormatCSV}, fileName: "a.csv"},
+		{name: "CSV GBK", modelType: reflect.TypeOf(model.DredgerDatum{}), opts: ExportOptions{Format: FileFormatCSV, Encoding: CSVEncodingGBK}, fileName: "a.csv"},
+		{name: "Parquet", modelType: reflect.TypeOf(model.DredgerDataHl{}), opts: ExportOptions{Format: FileFormatParquet}, fileName: "a.parquet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := exportColumns(tt.modelType, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 每列填入不同的值，小数部分能精确表示，文本格式不会引入误差
+			var records []reflect.Value
+			for n := 0; n < 5; n++ {
+				record := reflect.New(tt.modelType).Elem()
+				for i, c := range importableColumns(tt.modelType) {
+					field := record.Field(c.field)
+					switch {
+					case c.column == "record_time":
+						field.SetInt(start + int64(n)*1500)
+					case field.Kind() == reflect.Float64:
+						field.SetFloat(float64(n*1000+i) + 0.125)
+					case field.Kind() == reflect.Int32 || field.Kind() == reflect.Int64:
+						field.SetInt(int64(n*1000 + i))
+					}
+				}
+				records = append(records, record)
+			}
+
+			var buf bytes.Buffer
+			writer, err := newRowWriter(&buf, tt.opts, columns, tt.modelType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := make([]any, len(columns))
+			for _, record := range records {
+				exportValues(columns, record, loc, values)
+				if err := writer.WriteRow(values); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			src, format, err := openRowSource(bytes.NewReader(buf.Bytes()), tt.fileName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer src.Close()
+			if format != tt.opts.Format {
+				t.Errorf("format = %s, want %s", format, tt.opts.Format)
+			}
+			if !src.Next() {
+				t.Fatalf("没有表头: %v", src.Error())
+			}
+			header, _ := src.Columns()
+			mapping, err := buildColumnMapping(header, tt.modelType, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(mapping.report.UnknownHeaders) > 0 || len(mapping.report.UnmappedColumns) > 0 {
+				t.Errorf("UnknownHeaders = %q, UnmappedColumns = %q", mapping.report.UnknownHeaders, mapping.report.UnmappedColumns)
+			}
+
+			var n int
+			for ; src.Next(); n++ {
+				row, _ := src.Columns()
+				got := reflect.New(tt.modelType).Elem()
+				if _, rejection := mapping.convertRow(row, got); rejection != nil {
+					t.Fatalf("第 %d 行被拒绝: %s", n+1, rejection.message)
+				}
+				if n < len(records) && !reflect.DeepEqual(got.Interface(), records[n].Interface()) {
+					t.Errorf("第 %d 行 = %+v, want %+v", n+1, got.Interface(), records[n].Interface())
+				}
+			}
+			if err := src.Error(); err != nil {
+				t.Fatal(err)
+			}
+			if n != len(records) {
+				t.Errorf("读回 %d 行, want %d", n, len(records))
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"dredger/model"
+	"dredger/pkg/logger"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 导入批次状态
+const (
+	ImportBatchActive     = "active"
+	ImportBatchRolledBack = "rolled_back"
+)
+
+// ErrDuplicateImport 上传的文件与某个未回滚批次的文件内容完全相同
+var ErrDuplicateImport = errors.New("文件已导入过")
+
+var errImportBatchNotFound = errors.New("导入批次不存在")
+
+// ErrRollbackOverwritten 批次以 overwrite 模式覆盖过已有的行，覆盖前的值没有保存，不能回滚
+var ErrRollbackOverwritten = errors.New("批次覆盖过已有数据，不能回滚")
+
+// findActiveBatch 按文件 SHA-256 查找未回滚的批次，不存在时返回 nil
+func findActiveBatch(db *gorm.DB, fileHash string) (*model.ImportBatch, error) {
+	var batch model.ImportBatch
+	err := db.Where("file_hash = ? AND status = ?", fileHash, ImportBatchActive).Order("id DESC").Take(&batch).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Logger.Errorf("查询导入批次失败: %v", err)
+		return nil, err
+	}
+	return &batch, nil
+}
+
+// checkDuplicateImport 相同内容的文件已有未回滚的批次时返回 ErrDuplicateImport
+func checkDuplicateImport(db *gorm.DB, fileHash string) error {
+	batch, err := findActiveBatch(db, fileHash)
+	if err != nil || batch == nil {
+		return err
+	}
+	return fmt.Errorf("%w：%s 已于 %s 导入到 %s（批次 %d），如需重新导入请先回滚该批次",
+		ErrDuplicateImport, batch.FileName, batch.CreatedAt.Format(time.DateTime), batch.ShipName, batch.ID)
+}
+
+// ListImportBatches 按导入时间倒序返回导入批次，shipName 为空时返回所有船舶
+func (s *Service) ListImportBatches(shipName string, limit int) ([]*ImportBatch, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := s.db.Order("id DESC").Limit(limit)
+	if shipName != "" {
+		query = query.Where("ship_name = ?", shipName)
+	}
+	var records []*model.ImportBatch
+	if err := query.Find(&records).Error; err != nil {
+		logger.Logger.Errorf("查询导入批次列表失败: %v", err)
+		return nil, err
+	}
+
+	batches := make([]*ImportBatch, 0, len(records))
+	for _, r := range records {
+		batches = append(batches, toImportBatch(r))
+	}
+	return batches, nil
+}
+
+// RollbackImportBatch 删除批次新增的数据并修复 data_date。
+// overwrite 模式下被覆盖的行没有保存覆盖前的值，无法恢复，覆盖过数据的批次拒绝回滚并返回 ErrRollbackOverwritten
+func (s *Service) RollbackImportBatch(id int64) (*ImportBatch, error) {
+	var batch model.ImportBatch
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&batch, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errImportBatchNotFound
+			}
+			return err
+		}
+		if batch.Status != ImportBatchActive {
+			return fmt.Errorf("批次 %d 已回滚", batch.ID)
+		}
+		if batch.RowsOverwritten > 0 {
+			return fmt.Errorf("%w：批次 %d 覆盖了 %d 行，覆盖前的值无法恢复，请重新导入原来的数据", ErrRollbackOverwritten, batch.ID, batch.RowsOverwritten)
+		}
+
+		table, err := dataTable(batch.ShipName)
+		if err != nil {
+			return err
+		}
+		res := tx.Where("batch_id = ?", batch.ID).Delete(table)
+		if res.Error != nil {
+			return fmt.Errorf("删除批次数据失败: %v", res.Error)
+		}
+
+		now := time.Now()
+		batch.Status = ImportBatchRolledBack
+		batch.ActiveHash = nil
+		batch.RowsDeleted = res.RowsAffected
+		batch.RolledBackAt = &now
+		if err := tx.Save(&batch).Error; err != nil {
+			return err
+		}
+		return repairDataDates(tx, &batch)
+	})
+	if err != nil {
+		logger.Logger.Errorf("回滚导入批次 %d 失败: %v", id, err)
+		return nil, err
+	}
+
+	logger.Logger.Infof("导入批次 %d (%s) 已回滚，删除 %d 行", batch.ID, batch.FileName, batch.RowsDeleted)
+	return toImportBatch(&batch), nil
+}
+
+// repairDataDates 按施工数据表重新统计批次涉及的各天（含文件名中的起止日期）的 data_date
+func repairDataDates(tx *gorm.DB, batch *model.ImportBatch) error {
+	var from, to int64
+	for _, ms := range []int64{batch.StartDate, batch.EndDate, batch.StartTime, batch.EndTime} {
+		if ms == 0 {
+			continue
+		}
+		if from == 0 || ms < from {
+			from = ms
+		}
+		if ms > to {
+			to = ms
+		}
+	}
+	if from == 0 {
+		return nil
+	}
+	if _, _, err := RefreshDataDates(tx, batch.ShipName, from, to); err != nil {
+		return fmt.Errorf("修复 data_date 失败: %v", err)
+	}
+	return nil
+}
+
+func toImportBatch(b *model.ImportBatch) *ImportBatch {
+	batch := &ImportBatch{
+		ID:              b.ID,
+		JobID:           b.JobID,
+		FileName:        b.FileName,
+		FileHash:        b.FileHash,
+		ShipName:        b.ShipName,
+		Uploader:        b.Uploader,
+		Mode:            b.Mode,
+		Status:          b.Status,
+		CreatedAt:       b.CreatedAt.UnixMilli(),
+		StartTime:       b.StartTime,
+		EndTime:         b.EndTime,
+		RowsParsed:      b.RowsParsed,
+		RowsInserted:    b.RowsInserted,
+		RowsOverwritten: b.RowsOverwritten,
+		RowsUnchanged:   b.RowsUnchanged,
+		RowsSkipped:     b.RowsSkipped,
+		RowsDeleted:     b.RowsDeleted,
+		CanRollback:     b.Status == ImportBatchActive && b.RowsOverwritten == 0,
+	}
+	if b.RolledBackAt != nil {
+		batch.RolledBackAt = b.RolledBackAt.UnixMilli()
+	}
+	return batch
+}
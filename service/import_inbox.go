@@ -0,0 +1,223 @@
+package service
+
+import (
+	"dredger/pkg/conf"
+	"dredger/pkg/logger"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// 收件目录下存放处理结果的子目录
+const (
+	inboxProcessedDir = "processed"
+	inboxFailedDir    = "failed"
+)
+
+// importInbox 监听收件目录，文件写入完成后按文件名约定导入，
+// 处理完毕后连同结果 JSON 一起移动到 processed/ 或 failed/
+type importInbox struct {
+	svc     *Service
+	dir     string
+	mode    string
+	settle  time.Duration        // 文件最后一次变化后等待多久才认为写入完成
+	pending map[string]time.Time // 文件路径 -> 最后一次变化时间
+	queued  map[string]bool      // 已交给 worker、尚未处理完的文件
+	queue   chan string          // 等待导入的文件，由 worker 逐个处理
+	done    chan string          // worker 处理完的文件
+}
+
+// inboxQueueSize 等待导入的文件数上限，队列满时文件留在 pending 中，下一次再尝试
+const inboxQueueSize = 64
+
+// StartImportInbox 开始监听收件目录 dir，启动时目录中已有的文件也会被导入
+func (s *Service) StartImportInbox(dir string) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	for _, sub := range []string{"", inboxProcessedDir, inboxFailedDir} {
+		if err = os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err = watcher.Add(dir); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	inbox := &importInbox{
+		svc:     s,
+		dir:     dir,
+		mode:    conf.Conf.GetString("inbox.mode"),
+		settle:  conf.Conf.GetDuration("inbox.settle"),
+		pending: make(map[string]time.Time),
+		queued:  make(map[string]bool),
+		queue:   make(chan string, inboxQueueSize),
+		done:    make(chan string, inboxQueueSize+1), // 队列中的文件加上正在处理的一个，run 退出后 worker 也不会阻塞
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		watcher.Close()
+		return err
+	}
+	for _, e := range entries {
+		inbox.touch(filepath.Join(dir, e.Name()))
+	}
+
+	go inbox.work()
+	go inbox.run(watcher)
+	logger.Logger.Infof("开始监听导入收件目录 %s", dir)
+	return nil
+}
+
+// run 处理文件系统事件并把写入完成的文件交给 worker。导入可能耗时很长，这里不能阻塞，否则事件会积压或丢失
+func (in *importInbox) run(watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+	defer close(in.queue)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) || event.Has(fsnotify.Write) {
+				in.touch(event.Name)
+			} else if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+				delete(in.pending, event.Name)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Logger.Errorf("监听收件目录出错: %v", err)
+		case path := <-in.done:
+			delete(in.queued, path)
+		case <-ticker.C:
+			// 文件在 settle 时间内没有新的写入才处理，避免读到复制了一半的文件
+			for path, last := range in.pending {
+				if time.Since(last) < in.settle || in.queued[path] {
+					continue
+				}
+				select {
+				case in.queue <- path:
+					delete(in.pending, path)
+					in.queued[path] = true
+				default:
+					// 队列已满，下一次再尝试
+				}
+			}
+		}
+	}
+}
+
+// touch 记录需要处理的文件，忽略子目录、隐藏文件和 Office 的临时文件
+func (in *importInbox) touch(path string) {
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+		return
+	}
+	if !IsImportFile(name) {
+		return
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return
+	}
+	in.pending[path] = time.Now()
+}
+
+// work 逐个导入队列中的文件，导入本身仍经过导入任务的并发限制
+func (in *importInbox) work() {
+	for path := range in.queue {
+		in.process(path)
+		in.done <- path
+	}
+}
+
+func (in *importInbox) process(path string) {
+	// 排队期间文件可能已被移走或删除
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	name := filepath.Base(path)
+	job, err := in.importFile(path)
+	if err != nil {
+		job = &ImportJob{FileName: name, Status: ImportJobFailed, Error: err.Error(), CreatedAt: time.Now().UnixMilli()}
+	}
+
+	target := inboxFailedDir
+	if job.Status == ImportJobSucceeded {
+		target = inboxProcessedDir
+	}
+	dst, err := in.moveFile(path, target)
+	if err != nil {
+		logger.Logger.Errorf("移动收件目录文件 %s 失败: %v", name, err)
+		return
+	}
+
+	b, err := json.MarshalIndent(job, "", "  ")
+	if err == nil {
+		err = os.WriteFile(dst+".result.json", b, 0644)
+	}
+	if err != nil {
+		logger.Logger.Errorf("写入 %s 的导入结果失败: %v", name, err)
+	}
+
+	if job.Status == ImportJobSucceeded {
+		logger.Logger.Infof("收件目录文件 %s 导入完成，已移动到 %s", name, target)
+	} else {
+		logger.Logger.Warnf("收件目录文件 %s 导入失败: %s", name, job.Error)
+	}
+}
+
+// importFile 通过与上传相同的导入任务执行导入，并等待任务结束
+func (in *importInbox) importFile(path string) (*ImportJob, error) {
+	name := filepath.Base(path)
+	shipName, startDate, endDate, err := ParseImportFileName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	job, err := in.svc.SubmitImportJob(f, ImportOptions{
+		FileName:  name,
+		ShipName:  shipName,
+		Mode:      in.mode,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Uploader:  "inbox",
+	})
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	return in.svc.waitImportJob(job.ID)
+}
+
+// moveFile 将文件移动到收件目录的子目录 sub，重名时在文件名后追加时间
+func (in *importInbox) moveFile(path, sub string) (string, error) {
+	name := filepath.Base(path)
+	dst := filepath.Join(in.dir, sub, name)
+	if _, err := os.Stat(dst); err == nil {
+		ext := filepath.Ext(name)
+		dst = filepath.Join(in.dir, sub, fmt.Sprintf("%s_%s%s", strings.TrimSuffix(name, ext), time.Now().Format("20060102150405"), ext))
+	}
+	return dst, os.Rename(path, dst)
+}
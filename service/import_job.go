@@ -0,0 +1,422 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"dredger/model"
+	"dredger/pkg/logger"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 上传文件在任务执行完之前暂存在 dataDir 下的这个目录
+const importTmpDirName = "tmp"
+
+// 运行中的任务进度写库的最小间隔，阶段变化时立即写库
+const importJobSaveInterval = time.Second
+
+var errImportJobNotFound = errors.New("导入任务不存在")
+
+// importTracker 汇总一次导入的行数统计，并在阶段变化或每个批次写入后回调 onProgress
+type importTracker struct {
+	ImportProgress
+	mode       string
+	buckets    ImportBuckets
+	fileHash   string
+	format     string
+	batchID    int64
+	firstTime  int64 // 有效行中最早的记录时间（毫秒）
+	lastTime   int64 // 有效行中最晚的记录时间（毫秒）
+	mapping    *ColumnMappingReport
+	rejects    *importRejects
+	rejectPath string
+	preview    *ImportPreview
+	onProgress func(ImportProgress)
+}
+
+func (t *importTracker) observe(recordTime int64) {
+	if t.firstTime == 0 || recordTime < t.firstTime {
+		t.firstTime = recordTime
+	}
+	if recordTime > t.lastTime {
+		t.lastTime = recordTime
+	}
+}
+
+func (t *importTracker) reject(pos rowPosition, row []string, rej *rowRejection) {
+	t.RowsSkipped++
+	if t.rejects != nil {
+		t.rejects.add(pos, row, rej)
+	}
+}
+
+func (t *importTracker) report(phase string) {
+	t.Phase = phase
+	if t.onProgress != nil {
+		t.onProgress(t.ImportProgress)
+	}
+}
+
+func (t *importTracker) result() *ImportDataResult {
+	result := &ImportDataResult{
+		ImportedRows: t.RowsInserted,
+		ParsedRows:   t.RowsParsed,
+		SkippedRows:  t.RowsSkipped,
+		Mapping:      t.mapping,
+		Preview:      t.preview,
+		Mode:         t.mode,
+		Buckets:      t.buckets,
+		BatchID:      t.batchID,
+		FileHash:     t.fileHash,
+		Format:       t.format,
+	}
+	if t.rejectPath != "" {
+		result.RejectFile, result.RejectPath = filepath.Base(t.rejectPath), t.rejectPath
+	}
+	if t.preview != nil {
+		t.preview.StartTime, t.preview.EndTime = t.firstTime, t.lastTime
+	}
+	if t.rejects != nil && len(t.rejects.counts) > 0 {
+		result.Rejections = t.rejects.counts
+		result.RejectedRows = t.rejects.samples
+	}
+	return result
+}
+
+// importJobRun 内存中运行（或排队）的导入任务
+type importJobRun struct {
+	job      *model.ImportJob
+	cancel   context.CancelFunc
+	watchers map[chan ImportJob]struct{}
+	lastSave time.Time
+	done     chan struct{} // 任务结束后关闭
+}
+
+// importTmpDir 返回上传文件的暂存目录，未配置 dataDir 时使用系统临时目录，不依赖进程的工作目录
+func (s *Service) importTmpDir() string {
+	if s.dataDir == "" {
+		return os.TempDir()
+	}
+	return filepath.Join(s.dataDir, importTmpDirName)
+}
+
+// SubmitImportJob 将上传内容落盘后创建导入任务并在后台执行，立即返回任务信息
+func (s *Service) SubmitImportJob(src io.Reader, opts ImportOptions) (*ImportJob, error) {
+	opts.normalize()
+	// 未登记的船舶无法确定数据结构，不必排队
+	if _, err := lookupShip(opts.ShipName); err != nil {
+		return nil, err
+	}
+	tmpDir := s.importTmpDir()
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(tmpDir, "import-*"+filepath.Ext(opts.FileName))
+	if err != nil {
+		return nil, err
+	}
+	hasher := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(tmp, hasher), src); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("保存上传文件失败: %v", err)
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	// 排队前先拒绝重复文件，执行时 ImportData 会再检查一次
+	if err = checkDuplicateImport(s.db, hex.EncodeToString(hasher.Sum(nil))); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	job := &model.ImportJob{
+		FileName: opts.FileName,
+		ShipName: opts.ShipName,
+		Cover:    opts.Cover,
+		Mode:     opts.Mode,
+		Status:   ImportJobPending,
+		Phase:    ImportPhaseQueued,
+	}
+	if err = s.db.Create(job).Error; err != nil {
+		logger.Logger.Errorf("创建导入任务失败: %v", err)
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	opts.JobID = job.ID
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &importJobRun{
+		job:      job,
+		cancel:   cancel,
+		watchers: make(map[chan ImportJob]struct{}),
+		done:     make(chan struct{}),
+	}
+	s.jobMu.Lock()
+	s.jobs[job.ID] = run
+	s.jobMu.Unlock()
+
+	go s.runImportJob(ctx, run, tmp.Name(), opts)
+
+	return toImportJob(job), nil
+}
+
+func (s *Service) runImportJob(ctx context.Context, run *importJobRun, path string, opts ImportOptions) {
+	defer os.Remove(path)
+	defer run.cancel()
+
+	// 同一时间只执行一个导入，其余任务排队，避免多个大事务相互争用锁
+	select {
+	case s.importSlots <- struct{}{}:
+		defer func() { <-s.importSlots }()
+	case <-ctx.Done():
+		s.finishImportJob(run, nil, ctx.Err())
+		return
+	}
+
+	startedAt := time.Now()
+	s.updateImportJob(run, true, func(j *model.ImportJob) {
+		j.Status = ImportJobRunning
+		j.StartedAt = &startedAt
+	})
+
+	f, err := os.Open(path)
+	if err != nil {
+		s.finishImportJob(run, nil, err)
+		return
+	}
+	defer f.Close()
+
+	result, err := s.ImportData(ctx, f, opts, func(p ImportProgress) {
+		s.updateImportJob(run, false, func(j *model.ImportJob) {
+			j.Phase = p.Phase
+			j.RowsParsed = int64(p.RowsParsed)
+			j.RowsInserted = int64(p.RowsInserted)
+			j.RowsSkipped = int64(p.RowsSkipped)
+		})
+	})
+	s.finishImportJob(run, result, err)
+}
+
+// updateImportJob 修改任务状态并推送给订阅者；状态或阶段未变化且 force 为 false 时按 importJobSaveInterval 节流写库
+func (s *Service) updateImportJob(run *importJobRun, force bool, mutate func(j *model.ImportJob)) {
+	s.jobMu.Lock()
+	status, phase := run.job.Status, run.job.Phase
+	mutate(run.job)
+	if run.job.Status != status || run.job.Phase != phase {
+		force = true
+	}
+	snapshot := toImportJob(run.job)
+	for ch := range run.watchers {
+		select {
+		case ch <- *snapshot:
+		default: // 订阅者消费过慢时丢弃中间进度
+		}
+	}
+	save := force || time.Since(run.lastSave) >= importJobSaveInterval
+	if save {
+		run.lastSave = time.Now()
+	}
+	job := *run.job
+	s.jobMu.Unlock()
+
+	if save {
+		if err := s.db.Save(&job).Error; err != nil {
+			logger.Logger.Warnf("更新导入任务 %d 进度失败: %v", job.ID, err)
+		}
+	}
+}
+
+func (s *Service) finishImportJob(run *importJobRun, result *ImportDataResult, err error) {
+	finishedAt := time.Now()
+	s.updateImportJob(run, true, func(j *model.ImportJob) {
+		j.FinishedAt = &finishedAt
+		if result != nil {
+			j.RowsParsed = int64(result.ParsedRows)
+			j.RowsInserted = int64(result.ImportedRows)
+			j.RowsSkipped = int64(result.SkippedRows)
+			if b, err := json.Marshal(result); err == nil {
+				j.Result = string(b)
+			}
+		}
+		switch {
+		case err == nil:
+			j.Status = ImportJobSucceeded
+			j.Phase = ImportPhaseDone
+		case errors.Is(err, context.Canceled):
+			// 事务已回滚，没有任何数据写入
+			j.Status = ImportJobCanceled
+			j.RowsInserted = 0
+		default:
+			j.Status = ImportJobFailed
+			j.RowsInserted = 0
+			j.Error = err.Error()
+		}
+	})
+
+	s.jobMu.Lock()
+	for ch := range run.watchers {
+		delete(run.watchers, ch)
+		close(ch)
+	}
+	delete(s.jobs, run.job.ID)
+	s.jobMu.Unlock()
+	close(run.done)
+
+	if err != nil {
+		logger.Logger.Errorf("导入任务 %d (%s) 未完成: %v", run.job.ID, run.job.FileName, err)
+	} else {
+		logger.Logger.Infof("导入任务 %d (%s) 完成，写入 %d 行", run.job.ID, run.job.FileName, result.ImportedRows)
+	}
+}
+
+// waitImportJob 等待任务结束并返回最终状态
+func (s *Service) waitImportJob(id int64) (*ImportJob, error) {
+	s.jobMu.Lock()
+	run, ok := s.jobs[id]
+	s.jobMu.Unlock()
+	if ok {
+		<-run.done
+	}
+	return s.GetImportJob(id)
+}
+
+// GetImportJob 查询任务，运行中的任务返回内存中的最新进度
+func (s *Service) GetImportJob(id int64) (*ImportJob, error) {
+	s.jobMu.Lock()
+	if run, ok := s.jobs[id]; ok {
+		job := toImportJob(run.job)
+		s.jobMu.Unlock()
+		return job, nil
+	}
+	s.jobMu.Unlock()
+
+	var job model.ImportJob
+	if err := s.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errImportJobNotFound
+		}
+		logger.Logger.Errorf("查询导入任务 %d 失败: %v", id, err)
+		return nil, err
+	}
+	return toImportJob(&job), nil
+}
+
+// ListImportJobs 按创建时间倒序返回导入历史，shipName 为空时返回所有船舶
+func (s *Service) ListImportJobs(shipName string, limit int) ([]*ImportJob, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	query := s.db.Order("id DESC").Limit(limit)
+	if shipName != "" {
+		query = query.Where("ship_name = ?", shipName)
+	}
+	var records []*model.ImportJob
+	if err := query.Find(&records).Error; err != nil {
+		logger.Logger.Errorf("查询导入任务列表失败: %v", err)
+		return nil, err
+	}
+
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+	jobs := make([]*ImportJob, 0, len(records))
+	for _, r := range records {
+		if run, ok := s.jobs[r.ID]; ok {
+			r = run.job
+		}
+		jobs = append(jobs, toImportJob(r))
+	}
+	return jobs, nil
+}
+
+// CancelImportJob 取消排队或运行中的任务，正在执行的事务会被回滚
+func (s *Service) CancelImportJob(id int64) error {
+	s.jobMu.Lock()
+	run, ok := s.jobs[id]
+	s.jobMu.Unlock()
+	if !ok {
+		if _, err := s.GetImportJob(id); err != nil {
+			return err
+		}
+		return errors.New("导入任务已结束，无法取消")
+	}
+	run.cancel()
+	return nil
+}
+
+// WatchImportJob 返回任务当前状态以及后续进度推送通道；任务已结束时通道为 nil。
+// 任务结束后通道会被关闭，调用方不再需要时应调用 stop。
+func (s *Service) WatchImportJob(id int64) (current *ImportJob, updates <-chan ImportJob, stop func(), err error) {
+	s.jobMu.Lock()
+	run, ok := s.jobs[id]
+	if !ok {
+		s.jobMu.Unlock()
+		current, err = s.GetImportJob(id)
+		return current, nil, func() {}, err
+	}
+	ch := make(chan ImportJob, 16)
+	run.watchers[ch] = struct{}{}
+	current = toImportJob(run.job)
+	s.jobMu.Unlock()
+
+	stop = func() {
+		s.jobMu.Lock()
+		defer s.jobMu.Unlock()
+		if _, ok := run.watchers[ch]; ok {
+			delete(run.watchers, ch)
+			close(ch)
+		}
+	}
+	return current, ch, stop, nil
+}
+
+// InterruptStaleImportJobs 将上次进程退出时仍未结束的任务标记为失败
+func (s *Service) InterruptStaleImportJobs() {
+	err := s.db.Model(&model.ImportJob{}).
+		Where("status IN ?", []string{ImportJobPending, ImportJobRunning}).
+		Updates(map[string]any{"status": ImportJobFailed, "error": "服务重启，任务中断"}).Error
+	if err != nil {
+		logger.Logger.Warnf("重置未完成的导入任务失败: %v", err)
+	}
+}
+
+func toImportJob(j *model.ImportJob) *ImportJob {
+	job := &ImportJob{
+		ID:        j.ID,
+		FileName:  j.FileName,
+		ShipName:  j.ShipName,
+		Cover:     j.Cover,
+		Mode:      j.Mode,
+		Status:    j.Status,
+		Error:     j.Error,
+		CreatedAt: j.CreatedAt.UnixMilli(),
+		ImportProgress: ImportProgress{
+			Phase:        j.Phase,
+			RowsParsed:   int(j.RowsParsed),
+			RowsInserted: int(j.RowsInserted),
+			RowsSkipped:  int(j.RowsSkipped),
+		},
+	}
+	if j.Result != "" {
+		var result ImportDataResult
+		if err := json.Unmarshal([]byte(j.Result), &result); err == nil {
+			job.Result = &result
+		}
+	}
+	if j.StartedAt != nil {
+		job.StartedAt = j.StartedAt.UnixMilli()
+	}
+	if j.FinishedAt != nil {
+		job.FinishedAt = j.FinishedAt.UnixMilli()
+	}
+	return job
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"dredger/pkg/conf"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 表头末尾的单位或说明，例如 "流量(m3/h)"、"左耳轴吃水（m）"、"GPS1航速("
+var headerSuffixRe = regexp.MustCompile(`\s*[（(][^（()）]*[)）]?\s*$`)
+
+// 不参与导入映射的模型字段
+var importExcludedFields = map[string]bool{
+	"ID":       true,
+	"ShipName": true,
+	"BatchID":  true,
+}
+
+// mappedColumn 文件中的一列与模型字段的对应关系
+type mappedColumn struct {
+	index int // 文件中的列下标（从 0 开始）
+	field int // 模型字段下标
+	MappedColumn
+}
+
+// columnMapping 根据表头建立的列映射
+type columnMapping struct {
+	columns      []mappedColumn
+	timeColumn   int            // record_time 所在的列下标
+	minRowLength int            // 必填列全部存在所需的最少单元格数
+	loc          *time.Location // 解析不带时区的时间时使用的时区
+	report       *ColumnMappingReport
+}
+
+// modelColumn 模型中可导入的一个字段
+type modelColumn struct {
+	field   int
+	column  string // 数据库列名
+	comment string // gorm comment 中的中文名
+}
+
+func importableColumns(modelType reflect.Type) []modelColumn {
+	var columns []modelColumn
+	for i := 0; i < modelType.NumField(); i++ {
+		f := modelType.Field(i)
+		if importExcludedFields[f.Name] {
+			continue
+		}
+		column, comment := parseGormTag(f.Tag.Get("gorm"))
+		columns = append(columns, modelColumn{field: i, column: column, comment: comment})
+	}
+	return columns
+}
+
+// normalizeHeader 统一全角/半角括号与空白，便于比较
+func normalizeHeader(h string) string {
+	h = strings.TrimSpace(h)
+	h = strings.NewReplacer("（", "(", "）", ")", " ", "", " ", "", "\t", "").Replace(h)
+	return strings.ToLower(h)
+}
+
+func stripHeaderSuffix(h string) string {
+	return headerSuffixRe.ReplaceAllString(h, "")
+}
+
+// buildColumnMapping 按表头匹配模型字段：先精确匹配 gorm comment 中文名、数据库列名和配置的别名，
+// 再对去掉单位后的名称做唯一匹配。必填列缺失时返回错误。loc 为船舶所在时区
+func buildColumnMapping(header []string, modelType reflect.Type, loc *time.Location) (*columnMapping, error) {
+	columns := importableColumns(modelType)
+
+	exact := make(map[string]int)      // 规范化名称 -> columns 下标
+	stripped := make(map[string][]int) // 去掉单位后的名称 -> columns 下标
+	byColumn := make(map[string]int)   // 数据库列名 -> columns 下标
+	for i, c := range columns {
+		byColumn[c.column] = i
+		exact[normalizeHeader(c.column)] = i
+		if c.comment != "" {
+			exact[normalizeHeader(c.comment)] = i
+			key := stripHeaderSuffix(normalizeHeader(c.comment))
+			stripped[key] = append(stripped[key], i)
+		}
+	}
+	for column, aliases := range conf.Conf.GetStringMapStringSlice("import.aliases") {
+		i, ok := byColumn[column]
+		if !ok {
+			continue
+		}
+		for _, alias := range aliases {
+			exact[normalizeHeader(alias)] = i
+		}
+	}
+
+	m := &columnMapping{timeColumn: -1, loc: loc, report: &ColumnMappingReport{}}
+	claimed := make(map[int]string) // columns 下标 -> 已匹配的表头
+	pending := make(map[int]string) // 精确匹配失败、留待模糊匹配的表头
+	assign := func(index, col int, header string) {
+		claimed[col] = header
+		c := columns[col]
+		m.columns = append(m.columns, mappedColumn{
+			index: index,
+			field: c.field,
+			MappedColumn: MappedColumn{
+				Header:            header,
+				ColumnName:        c.column,
+				ColumnChineseName: c.comment,
+			},
+		})
+	}
+
+	for index, h := range header {
+		key := normalizeHeader(h)
+		if key == "" {
+			continue
+		}
+		col, ok := exact[key]
+		if !ok {
+			pending[index] = h
+			continue
+		}
+		if prev, dup := claimed[col]; dup {
+			m.report.UnknownHeaders = append(m.report.UnknownHeaders, fmt.Sprintf("%s（与 %s 重复）", h, prev))
+			continue
+		}
+		assign(index, col, h)
+	}
+	for index := range header {
+		h, ok := pending[index]
+		if !ok {
+			continue
+		}
+		var candidates []int
+		for _, col := range stripped[stripHeaderSuffix(normalizeHeader(h))] {
+			if _, taken := claimed[col]; !taken {
+				candidates = append(candidates, col)
+			}
+		}
+		if len(candidates) != 1 {
+			m.report.UnknownHeaders = append(m.report.UnknownHeaders, h)
+			continue
+		}
+		assign(index, candidates[0], h)
+	}
+
+	for i, c := range columns {
+		if _, ok := claimed[i]; !ok {
+			m.report.UnmappedColumns = append(m.report.UnmappedColumns, c.column)
+		}
+	}
+	for _, mc := range m.columns {
+		m.report.Mapped = append(m.report.Mapped, mc.MappedColumn)
+	}
+
+	var missing []string
+	for _, column := range conf.Conf.GetStringSlice("import.required") {
+		i, ok := byColumn[column]
+		if !ok {
+			continue
+		}
+		if _, found := claimed[i]; !found {
+			missing = append(missing, fmt.Sprintf("%s(%s)", columns[i].comment, column))
+			continue
+		}
+		for _, mc := range m.columns {
+			if mc.ColumnName == column && mc.index+1 > m.minRowLength {
+				m.minRowLength = mc.index + 1
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("文件缺少必需的列: %s", strings.Join(missing, "、"))
+	}
+
+	for _, mc := range m.columns {
+		if mc.ColumnName == "record_time" {
+			m.timeColumn = mc.index
+		}
+	}
+	if m.timeColumn < 0 {
+		return nil, fmt.Errorf("文件缺少时间列(record_time)")
+	}
+	if m.timeColumn+1 > m.minRowLength {
+		m.minRowLength = m.timeColumn + 1
+	}
+	return m, nil
+}
+
+// rowRejection 一行数据被拒绝的原因
+type rowRejection struct {
+	reason  string // Reject* 常量
+	column  string // 出错的数据库列名，列数不足时为空
+	value   string // 出错的单元格内容
+	message string
+}
+
+// convertRow 将一行单元格按映射写入 dst（模型结构体），返回该行的记录时间；数据不合法时返回拒绝原因
+func (m *columnMapping) convertRow(row []string, dst reflect.Value) (int64, *rowRejection) {
+	if len(row) < m.minRowLength {
+		return 0, &rowRejection{
+			reason:  RejectShortRow,
+			message: fmt.Sprintf("列数不足（%d/%d）", len(row), m.minRowLength),
+		}
+	}
+
+	var recordTime int64
+	for _, mc := range m.columns {
+		var cellVal string
+		if mc.index < len(row) { // excelize 会省略行尾的空单元格
+			cellVal = strings.TrimSpace(row[mc.index])
+		}
+		field := dst.Field(mc.field)
+		reject := func(reason, format string, args ...any) (int64, *rowRejection) {
+			return 0, &rowRejection{reason: reason, column: mc.ColumnName, value: cellVal, message: fmt.Sprintf(format, args...)}
+		}
+
+		// 时间字段（RecordTime）特殊处理
+		if mc.index == m.timeColumn {
+			timestamp, err := parseRecordTime(cellVal, m.loc)
+			if err != nil {
+				return reject(RejectBadTime, "时间字段 %s 格式错误（%v）: %q", mc.Header, err, cellVal)
+			}
+			recordTime = timestamp.UnixMilli()
+			field.SetInt(recordTime)
+			continue
+		}
+
+		// 根据字段类型进行转换和赋值，空单元格保留零值
+		switch field.Kind() {
+		case reflect.Float64, reflect.Float32:
+			if num, err := strconv.ParseFloat(cellVal, 64); err == nil {
+				field.SetFloat(num)
+			} else if cellVal != "" {
+				return reject(RejectBadFloat, "字段 %s 不是有效的数值: %q", mc.Header, cellVal)
+			}
+		case reflect.Int32, reflect.Int64:
+			if num, err := strconv.ParseInt(cellVal, 10, 64); err == nil {
+				field.SetInt(num)
+			} else if cellVal != "" {
+				return reject(RejectBadInt, "字段 %s 不是有效的整数: %q", mc.Header, cellVal)
+			}
+		case reflect.String:
+			field.SetString(cellVal)
+		default:
+			return reject(RejectUnsupported, "字段 %s 是不支持的类型 %s", mc.Header, field.Kind())
+		}
+	}
+	return recordTime, nil
+}
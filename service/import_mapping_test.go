@@ -0,0 +1,144 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"dredger/model"
+	"dredger/pkg/conf"
+
+	"github.com/spf13/viper"
+)
+
+// useImportConf 测试期间使用只包含导入配置的 conf.Conf，与 dredger.yaml 中的默认值一致
+func useImportConf(t *testing.T) {
+	t.Helper()
+	prev := conf.Conf
+	conf.Conf = viper.New()
+	conf.Conf.Set("import.required", []string{"record_time", "flow_rate", "concentration"})
+	conf.Conf.Set("import.aliases", map[string][]string{"record_time": {"记录时间", "采集时间"}})
+	conf.Conf.Set("import.previewSamples", 10)
+	t.Cleanup(func() { conf.Conf = prev })
+}
+
+func TestBuildColumnMapping(t *testing.T) {
+	useImportConf(t)
+
+	tests := []struct {
+		name             string
+		header           []string
+		want             map[string]string // 表头 -> 数据库列名
+		wantUnknown      []string
+		wantTimeColumn   int
+		wantMinRowLength int
+		wantErr          bool
+	}{
+		{
+			name:             "中文名",
+			header:           []string{"时间", "流量", "浓度"},
+			want:             map[string]string{"时间": "record_time", "流量": "flow_rate", "浓度": "concentration"},
+			wantMinRowLength: 3,
+		},
+		{
+			name:             "数据库列名不区分大小写",
+			header:           []string{"Record_Time", "FLOW_RATE", "concentration"},
+			want:             map[string]string{"Record_Time": "record_time", "FLOW_RATE": "flow_rate", "concentration": "concentration"},
+			wantMinRowLength: 3,
+		},
+		{
+			name:             "去掉单位后匹配",
+			header:           []string{"时间", "流量（m3/h）", " 浓度 (%)"},
+			want:             map[string]string{"时间": "record_time", "流量（m3/h）": "flow_rate", " 浓度 (%)": "concentration"},
+			wantMinRowLength: 3,
+		},
+		{
+			name:             "配置的别名",
+			header:           []string{"流量", "浓度", "", "采集时间"},
+			want:             map[string]string{"采集时间": "record_time", "流量": "flow_rate", "浓度": "concentration"},
+			wantTimeColumn:   3,
+			wantMinRowLength: 4,
+		},
+		{
+			name:             "重复和无法识别的列",
+			header:           []string{"流量", "时间", "流量", "浓度", "备注"},
+			want:             map[string]string{"时间": "record_time", "流量": "flow_rate", "浓度": "concentration"},
+			wantUnknown:      []string{"流量（与 流量 重复）", "备注"},
+			wantTimeColumn:   1,
+			wantMinRowLength: 4,
+		},
+		{
+			name:    "缺少必需的列",
+			header:  []string{"时间", "流量"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := buildColumnMapping(tt.header, reflect.TypeOf(model.DredgerDatum{}), time.UTC)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("buildColumnMapping() 应返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make(map[string]string)
+			for _, mc := range m.report.Mapped {
+				got[mc.Header] = mc.ColumnName
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mapped = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(m.report.UnknownHeaders, tt.wantUnknown) {
+				t.Errorf("UnknownHeaders = %q, want %q", m.report.UnknownHeaders, tt.wantUnknown)
+			}
+			if m.timeColumn != tt.wantTimeColumn || m.minRowLength != tt.wantMinRowLength {
+				t.Errorf("timeColumn, minRowLength = %d, %d, want %d, %d", m.timeColumn, m.minRowLength, tt.wantTimeColumn, tt.wantMinRowLength)
+			}
+		})
+	}
+}
+
+func TestConvertRow(t *testing.T) {
+	useImportConf(t)
+	m, err := buildColumnMapping([]string{"时间", "流量", "浓度", "密度"}, reflect.TypeOf(model.DredgerDatum{}), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		row        []string
+		want       model.DredgerDatum
+		wantReason string
+	}{
+		{
+			name: "省略行尾的空单元格",
+			row:  []string{"2026-03-10 08:00:00", "1200.5", " 30 "},
+			want: model.DredgerDatum{RecordTime: 1773129600000, FlowRate: 1200.5, Concentration: 30},
+		},
+		{name: "列数不足", row: []string{"2026-03-10 08:00:00", "1200.5"}, wantReason: RejectShortRow},
+		{name: "时间格式错误", row: []string{"昨天", "1200.5", "30"}, wantReason: RejectBadTime},
+		{name: "数值格式错误", row: []string{"2026-03-10 08:00:00", "1,200", "30"}, wantReason: RejectBadFloat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got model.DredgerDatum
+			_, rejection := m.convertRow(tt.row, reflect.ValueOf(&got).Elem())
+			if rejection != nil || tt.wantReason != "" {
+				if rejection == nil || rejection.reason != tt.wantReason {
+					t.Errorf("convertRow() rejection = %+v, want %s", rejection, tt.wantReason)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("convertRow() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"dredger/pkg/conf"
+	"dredger/pkg/logger"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 拒绝文件存放在 dataDir 下的这个目录，通过 /v1/files/serve 下载
+const rejectDirName = "rejects"
+
+// importRejects 收集被拒绝的行：按原因计数、保留前 N 条明细，并把完整的原始行写入拒绝文件。
+// 拒绝文件保留原表头和列位置，末尾追加"错误原因"、"原始行号"和"原始工作表"三列，修正后可直接重新导入。
+type importRejects struct {
+	header  []string
+	path    string
+	limit   int
+	counts  map[string]int
+	samples []RejectedRow
+
+	file    *excelize.File
+	sw      *excelize.StreamWriter
+	written int   // 已写入拒绝文件的数据行数
+	err     error // 写拒绝文件出错后不再写入，不影响导入本身
+}
+
+// newImportRejects dir 为空时只计数和保留明细，不生成拒绝文件，用于没有副作用的预览
+func newImportRejects(dir, fileName string, header []string) *importRejects {
+	r := &importRejects{
+		header: header,
+		limit:  conf.Conf.GetInt("import.rejectSamples"),
+		counts: make(map[string]int),
+	}
+	if dir != "" {
+		base := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+		r.path = filepath.Join(dir, fmt.Sprintf("%s_rejects_%s.xlsx", base, time.Now().Format("20060102150405")))
+	}
+	return r
+}
+
+func (r *importRejects) add(pos rowPosition, row []string, rej *rowRejection) {
+	r.counts[rej.reason]++
+	if len(r.samples) < r.limit {
+		r.samples = append(r.samples, RejectedRow{
+			Sheet:   pos.Sheet,
+			Row:     pos.Row,
+			Reason:  rej.reason,
+			Column:  rej.column,
+			Value:   rej.value,
+			Message: rej.message,
+		})
+	}
+	if r.err != nil || r.path == "" {
+		return
+	}
+	if r.err = r.write(pos, row, rej.message); r.err != nil {
+		logger.Logger.Warnf("写入拒绝文件失败: %v", r.err)
+	}
+}
+
+func (r *importRejects) write(pos rowPosition, row []string, message string) error {
+	if r.sw == nil {
+		r.file = excelize.NewFile()
+		sw, err := r.file.NewStreamWriter(r.file.GetSheetName(0))
+		if err != nil {
+			return err
+		}
+		r.sw = sw
+		if err = r.sw.SetRow("A1", r.cells(r.header, "错误原因", "原始行号", "原始工作表")); err != nil {
+			return err
+		}
+	}
+	if r.written+1 >= excelize.TotalRows {
+		return fmt.Errorf("被拒绝的行超过单个工作表上限 %d 行，其余行不再写入", excelize.TotalRows-1)
+	}
+	r.written++
+	cell, err := excelize.CoordinatesToCellName(1, r.written+1)
+	if err != nil {
+		return err
+	}
+	// 原始行可能短于表头，补齐后错误信息才能落在固定的列上
+	padded := make([]string, len(r.header))
+	copy(padded, row)
+	if len(row) > len(padded) {
+		padded = row
+	}
+	return r.sw.SetRow(cell, r.cells(padded, message, pos.Row, pos.Sheet))
+}
+
+func (r *importRejects) cells(values []string, extra ...any) []any {
+	cells := make([]any, 0, len(values)+len(extra))
+	for _, v := range values {
+		cells = append(cells, v)
+	}
+	return append(cells, extra...)
+}
+
+// save 保存拒绝文件并返回完整路径，没有被拒绝的行或写入失败时返回空字符串
+func (r *importRejects) save() string {
+	if r.sw == nil {
+		return ""
+	}
+	err := r.sw.Flush()
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(r.path), 0755)
+	}
+	if err == nil {
+		err = r.file.SaveAs(r.path)
+	}
+	if err != nil {
+		logger.Logger.Errorf("保存拒绝文件 %s 失败: %v", r.path, err)
+		return ""
+	}
+	return r.path
+}
+
+func (r *importRejects) close() {
+	if r.file != nil {
+		r.file.Close()
+	}
+}
+
+// RejectDir 返回拒绝文件所在的目录，/v1/files/serve 允许访问该目录下的文件
+func (s *Service) RejectDir() string {
+	return filepath.Join(s.dataDir, rejectDirName)
+}
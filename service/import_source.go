@@ -0,0 +1,436 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+)
+
+// 支持导入和导出的文件格式
+const (
+	FileFormatXLSX    = "xlsx"
+	FileFormatCSV     = "csv"
+	FileFormatParquet = "parquet"
+)
+
+var (
+	xlsxMagic    = []byte("PK\x03\x04")
+	parquetMagic = []byte("PAR1")
+	utf8BOM      = []byte("\xEF\xBB\xBF")
+)
+
+// 按扩展名识别的可导入文件
+var importExtensions = map[string]bool{
+	".xlsx":    true,
+	".csv":     true,
+	".parquet": true,
+}
+
+// IsImportFile 文件扩展名是否为可导入的格式（XLSX、CSV、Parquet）
+func IsImportFile(name string) bool {
+	return importExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// 检测 CSV 编码和分隔符时读取的字节数
+const csvSniffSize = 64 << 10
+
+// rowSource 逐行读取导入文件，第一行为表头
+type rowSource interface {
+	Next() bool
+	Columns() ([]string, error)
+	Error() error
+	Close() error
+}
+
+// sheetSource 由多个工作表组成的导入文件，能给出当前行所在的工作表和表内行号
+type sheetSource interface {
+	Position() rowPosition
+}
+
+// rowPosition 一行在导入文件中的位置，行号从表头的第 1 行算起；只有 XLSX 有工作表名称
+type rowPosition struct {
+	Sheet string
+	Row   int
+}
+
+func (p rowPosition) String() string {
+	if p.Sheet == "" {
+		return fmt.Sprintf("第 %d 行", p.Row)
+	}
+	return fmt.Sprintf("工作表 %s 第 %d 行", p.Sheet, p.Row)
+}
+
+// openRowSource 先按文件头的魔数识别 XLSX 和 Parquet，其余按扩展名识别为 CSV
+func openRowSource(file io.ReadSeeker, fileName string) (rowSource, string, error) {
+	magic := make([]byte, 4)
+	n, err := io.ReadFull(file, magic)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, "", err
+	}
+	magic = magic[:n]
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	switch {
+	case bytes.Equal(magic, xlsxMagic):
+		src, err := openXLSXSource(file)
+		return src, FileFormatXLSX, err
+	case bytes.Equal(magic, parquetMagic):
+		src, err := openParquetSource(file)
+		return src, FileFormatParquet, err
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv", ".txt":
+		src, err := openCSVSource(file)
+		return src, FileFormatCSV, err
+	case ".xlsx":
+		return nil, "", errors.New("文件不是有效的 XLSX 文件")
+	case ".parquet":
+		return nil, "", errors.New("文件不是有效的 Parquet 文件")
+	}
+	return nil, "", fmt.Errorf("不支持的文件格式 %s，仅支持 XLSX、CSV 和 Parquet", filepath.Ext(fileName))
+}
+
+// 读取单元格的原始值而不是按数字格式显示的文本，日期单元格得到 Excel 序列日期，数值不会因显示格式丢失精度
+var xlsxRawValue = excelize.Options{RawCellValue: true}
+
+// xlsxSource 读取第一个工作表；之后表头与第一个工作表完全相同的工作表视为续表，
+// 跳过其表头继续读取（导出时超过单表行数上限会拆成多个工作表），其他工作表忽略
+type xlsxSource struct {
+	file   *excelize.File
+	sheets []string
+	header []string
+	rows   *excelize.Rows
+	record []string
+	sheet  string // 当前工作表
+	row    int    // 当前行在工作表中的行号
+	err    error
+}
+
+func openXLSXSource(r io.Reader) (*xlsxSource, error) {
+	// 工作表超过 StreamChunkSize 时 excelize 会将其解压到临时文件，配合 Rows 迭代器逐行读取，避免整表载入内存
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		f.Close()
+		return nil, errors.New("文件中没有工作表")
+	}
+	rows, err := f.Rows(sheets[0])
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &xlsxSource{file: f, sheets: sheets[1:], rows: rows, sheet: sheets[0]}, nil
+}
+
+func (s *xlsxSource) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	if s.rows.Next() {
+		s.row++
+		// Rows.Columns 每行只能读取一次
+		if s.record, s.err = s.rows.Columns(xlsxRawValue); s.err != nil {
+			return false
+		}
+		if s.header == nil {
+			s.header = s.record
+		}
+		return true
+	}
+	if s.err = s.rows.Error(); s.err != nil || s.header == nil {
+		return false
+	}
+	for len(s.sheets) > 0 {
+		name := s.sheets[0]
+		s.sheets = s.sheets[1:]
+		s.rows.Close()
+		if s.rows, s.err = s.file.Rows(name); s.err != nil {
+			return false
+		}
+		if !s.rows.Next() {
+			continue
+		}
+		header, err := s.rows.Columns(xlsxRawValue)
+		if err != nil {
+			s.err = err
+			return false
+		}
+		if slices.Equal(header, s.header) && s.rows.Next() {
+			// 续表的行号从该工作表的表头重新计算
+			s.sheet, s.row = name, 2
+			s.record, s.err = s.rows.Columns(xlsxRawValue)
+			return s.err == nil
+		}
+	}
+	return false
+}
+
+func (s *xlsxSource) Position() rowPosition {
+	return rowPosition{Sheet: s.sheet, Row: s.row}
+}
+
+func (s *xlsxSource) Columns() ([]string, error) {
+	return s.record, nil
+}
+
+func (s *xlsxSource) Error() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.rows.Error()
+}
+
+func (s *xlsxSource) Close() error {
+	s.rows.Close()
+	return s.file.Close()
+}
+
+// csvSource 读取 UTF-8（可带 BOM）或 GBK 编码的 CSV，分隔符根据首行自动识别
+type csvSource struct {
+	reader *csv.Reader
+	record []string
+	err    error
+}
+
+func openCSVSource(r io.Reader) (*csvSource, error) {
+	br := bufio.NewReaderSize(r, csvSniffSize)
+	sniff, err := br.Peek(csvSniffSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+
+	var input io.Reader = br
+	if bytes.HasPrefix(sniff, utf8BOM) {
+		br.Discard(len(utf8BOM))
+		sniff = sniff[len(utf8BOM):]
+	} else if !utf8.Valid(trimPartialRune(sniff, len(sniff) == csvSniffSize)) {
+		// 不是合法的 UTF-8 时按 GB18030（兼容 GBK）解码，船上自动化系统导出的 CSV 多为此编码
+		input = transform.NewReader(br, simplifiedchinese.GB18030.NewDecoder())
+	}
+
+	reader := csv.NewReader(input)
+	reader.Comma = sniffDelimiter(sniff)
+	reader.FieldsPerRecord = -1 // 列数由映射校验，不在这里报错
+	reader.LazyQuotes = true
+	return &csvSource{reader: reader}, nil
+}
+
+// trimPartialRune 去掉被截断在缓冲区末尾的不完整字符
+func trimPartialRune(b []byte, truncated bool) []byte {
+	if !truncated {
+		return b
+	}
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i]
+			}
+			break
+		}
+	}
+	return b
+}
+
+// sniffDelimiter 取首行中出现次数最多的分隔符，默认逗号
+func sniffDelimiter(sniff []byte) rune {
+	line, _, _ := bytes.Cut(sniff, []byte("\n"))
+	delimiter, most := ',', bytes.Count(line, []byte(","))
+	for _, c := range []rune{'\t', ';'} {
+		if n := bytes.Count(line, []byte(string(c))); n > most {
+			delimiter, most = c, n
+		}
+	}
+	return delimiter
+}
+
+func (s *csvSource) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	s.record, s.err = s.reader.Read()
+	return s.err == nil
+}
+
+func (s *csvSource) Columns() ([]string, error) {
+	return s.record, nil
+}
+
+func (s *csvSource) Error() error {
+	if errors.Is(s.err, io.EOF) {
+		return nil
+	}
+	return s.err
+}
+
+func (s *csvSource) Close() error {
+	return nil
+}
+
+// parquetSource 按行组读取扁平结构的 Parquet 文件，表头为列名；时间戳列转换为 ISO-8601 字符串
+type parquetSource struct {
+	file       *parquet.File
+	header     []string
+	timeUnits  []time.Duration // 时间戳列的单位，非时间戳列为 0
+	rowGroup   int
+	rows       parquet.Rows
+	buf        []parquet.Row
+	pos, count int
+	record     []string
+	err        error
+}
+
+func openParquetSource(r io.ReadSeeker) (*parquetSource, error) {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	ra, ok := r.(io.ReaderAt)
+	if !ok {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		ra = bytes.NewReader(b)
+	}
+
+	f, err := parquet.OpenFile(ra, size)
+	if err != nil {
+		return nil, err
+	}
+	schema := f.Schema()
+	columns := schema.Columns()
+	src := &parquetSource{
+		file:      f,
+		header:    make([]string, len(columns)),
+		timeUnits: make([]time.Duration, len(columns)),
+		buf:       make([]parquet.Row, 256),
+	}
+	for i, path := range columns {
+		src.header[i] = strings.Join(path, ".")
+		leaf, _ := schema.Lookup(path...)
+		if lt := leaf.Node.Type().LogicalType(); lt != nil && lt.Timestamp != nil {
+			switch {
+			case lt.Timestamp.Unit.Millis != nil:
+				src.timeUnits[i] = time.Millisecond
+			case lt.Timestamp.Unit.Micros != nil:
+				src.timeUnits[i] = time.Microsecond
+			default:
+				src.timeUnits[i] = time.Nanosecond
+			}
+		}
+	}
+	return src, nil
+}
+
+func (s *parquetSource) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	// 第一次调用返回表头
+	if s.record == nil {
+		s.record = s.header
+		return true
+	}
+	for s.pos >= s.count {
+		if !s.fill() {
+			return false
+		}
+	}
+	row := s.buf[s.pos]
+	s.pos++
+	s.record = make([]string, len(s.header))
+	for _, v := range row {
+		if c := v.Column(); c < len(s.record) {
+			s.record[c] = s.format(v, s.timeUnits[c])
+		}
+	}
+	return true
+}
+
+// fill 从当前行组读取下一批行，当前行组读完时切换到下一个行组
+func (s *parquetSource) fill() bool {
+	for {
+		if s.rows == nil {
+			groups := s.file.RowGroups()
+			if s.rowGroup >= len(groups) {
+				return false
+			}
+			s.rows = groups[s.rowGroup].Rows()
+			s.rowGroup++
+		}
+		n, err := s.rows.ReadRows(s.buf)
+		s.pos, s.count = 0, n
+		if err != nil {
+			s.rows.Close()
+			s.rows = nil
+			if !errors.Is(err, io.EOF) {
+				s.err = err
+				return false
+			}
+		}
+		if n > 0 {
+			return true
+		}
+	}
+}
+
+func (s *parquetSource) format(v parquet.Value, unit time.Duration) string {
+	if v.IsNull() {
+		return ""
+	}
+	switch v.Kind() {
+	case parquet.Boolean:
+		return strconv.FormatBool(v.Boolean())
+	case parquet.Int32:
+		return strconv.FormatInt(int64(v.Int32()), 10)
+	case parquet.Int64:
+		if unit > 0 {
+			// 时间戳是绝对时间，带上时区偏移，解析时不受船舶时区影响
+			return time.Unix(0, v.Int64()*int64(unit)).Format(time.RFC3339Nano)
+		}
+		return strconv.FormatInt(v.Int64(), 10)
+	case parquet.Float:
+		return strconv.FormatFloat(float64(v.Float()), 'f', -1, 32)
+	case parquet.Double:
+		return strconv.FormatFloat(v.Double(), 'f', -1, 64)
+	default:
+		return string(v.ByteArray())
+	}
+}
+
+func (s *parquetSource) Columns() ([]string, error) {
+	return s.record, nil
+}
+
+func (s *parquetSource) Error() error {
+	return s.err
+}
+
+func (s *parquetSource) Close() error {
+	if s.rows != nil {
+		return s.rows.Close()
+	}
+	return nil
+}
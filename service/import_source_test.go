@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// readRows 读出 rowSource 中的所有行（含表头）
+func readRows(t *testing.T, src rowSource) [][]string {
+	t.Helper()
+	var rows [][]string
+	for src.Next() {
+		row, err := src.Columns()
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	if err := src.Error(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestOpenCSVSource(t *testing.T) {
+	gbk, err := simplifiedchinese.GBK.NewEncoder().String("时间,流量\n2026-03-10 08:00:00,1200\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"时间", "流量"}, {"2026-03-10 08:00:00", "1200"}}
+
+	tests := []struct {
+		name  string
+		input string
+		want  [][]string
+	}{
+		{name: "UTF-8", input: "时间,流量\n2026-03-10 08:00:00,1200\n", want: want},
+		{name: "UTF-8 带 BOM", input: "\xef\xbb\xbf时间,流量\r\n2026-03-10 08:00:00,1200\r\n", want: want},
+		{name: "GBK", input: gbk, want: want},
+		{name: "制表符分隔", input: "时间\t流量\n2026-03-10 08:00:00\t1200\n", want: want},
+		{name: "分号分隔且数值含逗号", input: "时间;流量\n2026-03-10 08:00:00;1,5\n", want: [][]string{{"时间", "流量"}, {"2026-03-10 08:00:00", "1,5"}}},
+		{name: "列数不一致不报错", input: "时间,流量\n2026-03-10 08:00:00\n", want: [][]string{{"时间", "流量"}, {"2026-03-10 08:00:00"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := openCSVSource(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := readRows(t, src); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenCSVSourceLongUTF8(t *testing.T) {
+	// 检测编码的缓冲区在多字节字符中间截断时仍应识别为 UTF-8
+	header := "时间," + strings.Repeat("流", csvSniffSize/3)
+	src, err := openCSVSource(strings.NewReader("x" + header + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := readRows(t, src)
+	if len(rows) != 1 || rows[0][0] != "x时间" {
+		t.Errorf("rows[0][0] = %q, want %q", rows[0][0], "x时间")
+	}
+}
+
+func TestTrimPartialRune(t *testing.T) {
+	han := []byte("时间") // 每个字 3 字节
+	tests := []struct {
+		name      string
+		b         []byte
+		truncated bool
+		want      []byte
+	}{
+		{name: "完整", b: han, truncated: true, want: han},
+		{name: "截断在字符中间", b: han[:5], truncated: true, want: han[:3]},
+		{name: "只剩首字节", b: han[:4], truncated: true, want: han[:3]},
+		{name: "未截断时不处理", b: han[:5], want: han[:5]},
+		{name: "ASCII", b: []byte("abc"), truncated: true, want: []byte("abc")},
+		{name: "非法字节不是截断", b: []byte{'a', 0xff}, truncated: true, want: []byte{'a', 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := trimPartialRune(tt.b, tt.truncated); !bytes.Equal(got, tt.want) {
+				t.Errorf("trimPartialRune() = %x, want %x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSniffDelimiter(t *testing.T) {
+	tests := []struct {
+		sniff string
+		want  rune
+	}{
+		{sniff: "a,b,c\n1;2;3;4", want: ','},
+		{sniff: "a\tb\tc", want: '\t'},
+		{sniff: "a;b;c\n", want: ';'},
+		{sniff: "a,b;c;d", want: ';'},
+		{sniff: "时间", want: ','},
+	}
+
+	for _, tt := range tests {
+		if got := sniffDelimiter([]byte(tt.sniff)); got != tt.want {
+			t.Errorf("sniffDelimiter(%q) = %q, want %q", tt.sniff, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXSourceContinuationSheets(t *testing.T) {
+	f := excelize.NewFile()
+	header := []any{"时间", "流量"}
+	sheets := []struct {
+		name string
+		rows [][]any
+	}{
+		{name: "数据1", rows: [][]any{header, {"2026-03-10 08:00:00", 1200}, {"2026-03-10 08:00:01", 1300}}},
+		{name: "说明", rows: [][]any{{"备注"}, {"表头不同的工作表不读取"}}},
+		{name: "数据2", rows: [][]any{header, {"2026-03-10 08:00:02", 1400}}},
+		{name: "数据3", rows: [][]any{header}},
+	}
+	for i, s := range sheets {
+		if i == 0 {
+			if err := f.SetSheetName(f.GetSheetName(0), s.name); err != nil {
+				t.Fatal(err)
+			}
+		} else if _, err := f.NewSheet(s.name); err != nil {
+			t.Fatal(err)
+		}
+		for r, row := range s.rows {
+			cell, _ := excelize.CoordinatesToCellName(1, r+1)
+			if err := f.SetSheetRow(s.name, cell, &row); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := openXLSXSource(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	var rows [][]string
+	var positions []rowPosition
+	for src.Next() {
+		row, _ := src.Columns()
+		rows = append(rows, row)
+		positions = append(positions, src.Position())
+	}
+	if err := src.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	wantRows := [][]string{
+		{"时间", "流量"},
+		{"2026-03-10 08:00:00", "1200"},
+		{"2026-03-10 08:00:01", "1300"},
+		{"2026-03-10 08:00:02", "1400"},
+	}
+	wantPositions := []rowPosition{{"数据1", 1}, {"数据1", 2}, {"数据1", 3}, {"数据2", 2}}
+	if !reflect.DeepEqual(rows, wantRows) {
+		t.Errorf("rows = %q, want %q", rows, wantRows)
+	}
+	if !reflect.DeepEqual(positions, wantPositions) {
+		t.Errorf("positions = %v, want %v", positions, wantPositions)
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"dredger/pkg/logger"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// 运行状态
+const (
+	StateDredging   = "dredging"                 // 挖泥：有产量且泥泵运转
+	StateSpudMoving = "spud-moving"              // 移船步进：台车行程变化且没有产量
+	StateSwinging   = "swinging-without-cutting" // 空横移：横移但绞刀未转
+	StateIdlePump   = "idle-pumping"             // 空泵：泥泵运转但没有产量
+	StateStopped    = "stopped"                  // 停机：泥泵、绞刀和横移都停止
+	StateOther      = "other"                    // 其它：如只有绞刀转动
+	StateNoData     = "no-data"                  // 数据中断
+)
+
+// stateNames 运行状态的中文名称
+var stateNames = map[string]string{
+	StateDredging:   "挖泥",
+	StateSpudMoving: "移船步进",
+	StateSwinging:   "空横移",
+	StateIdlePump:   "空泵",
+	StateStopped:    "停机",
+	StateOther:      "其它",
+	StateNoData:     "数据中断",
+}
+
+// 运行状态阈值的默认值
+const (
+	defaultStateMinOutputRate  = 10  // m³/h
+	defaultStateMinPumpSpeed   = 50  // r/min
+	defaultStateMinCutterSpeed = 1   // r/min
+	defaultStateMinSwingSpeed  = 0.5 // 横移速度
+	defaultStateMinSpudStep    = 0.1 // m，相邻记录的台车行程变化
+)
+
+func (r ShipStateRule) normalize() (ShipStateRule, error) {
+	for _, v := range []float64{r.MinOutputRate, r.MinPumpSpeed, r.MinCutterSpeed, r.MinSwingSpeed, r.MinSpudStep} {
+		if v < 0 {
+			return r, fmt.Errorf("运行状态规则: %w", errNegativeThreshold)
+		}
+	}
+	defaults := []struct {
+		value *float64
+		def   float64
+	}{
+		{&r.MinOutputRate, defaultStateMinOutputRate},
+		{&r.MinPumpSpeed, defaultStateMinPumpSpeed},
+		{&r.MinCutterSpeed, defaultStateMinCutterSpeed},
+		{&r.MinSwingSpeed, defaultStateMinSwingSpeed},
+		{&r.MinSpudStep, defaultStateMinSpudStep},
+	}
+	for _, d := range defaults {
+		if *d.value == 0 {
+			*d.value = d.def
+		}
+	}
+	return r, nil
+}
+
+// classify 判断 prev 到 cur 这个采样间隔的运行状态，按挖泥、移船步进、空横移、空泵、停机的顺序判断
+func (r ShipStateRule) classify(prev, cur *record) string {
+	pumping := prev.PumpSpeed >= r.MinPumpSpeed
+	cutting := prev.CutterSpeed >= r.MinCutterSpeed
+	swinging := math.Abs(prev.TransverseSpeed) >= r.MinSwingSpeed
+	switch {
+	case prev.OutputRate >= r.MinOutputRate && pumping:
+		return StateDredging
+	case math.Abs(cur.TrolleyTravel-prev.TrolleyTravel) >= r.MinSpudStep:
+		return StateSpudMoving
+	case swinging && !cutting:
+		return StateSwinging
+	case pumping:
+		return StateIdlePump
+	case !cutting && !swinging:
+		return StateStopped
+	}
+	return StateOther
+}
+
+// timeInState 统计同一班次内相邻记录之间各运行状态的时长（分钟），超过中断阈值的间隔计为数据中断
+func timeInState(records []*record, loc *time.Location, ship *ShipConfig) map[string]float64 {
+	maxGap := ship.Duration.maxGap()
+	states := make(map[string]float64)
+	eachInterval(records, loc, func(prev, cur *record) {
+		state := StateNoData
+		if cur.RecordTime-prev.RecordTime <= maxGap {
+			state = ship.States.classify(prev, cur)
+		}
+		states[state] += float64(cur.RecordTime-prev.RecordTime) / float64(time.Minute/time.Millisecond)
+	})
+	return states
+}
+
+// GetStateStats 统计各班组（按天或按周）的运行状态时长和挖泥时间利用率，dimension、granularity 的含义同 GetShiftStatSeries
+func (s *Service) GetStateStats(shipName string, startTime, endTime int64, dimension, granularity string) ([]*StateStat, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	loc := ShipLocation(shipName)
+
+	records, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询运行状态数据失败: %v", shipName, err)
+		return nil, err
+	}
+	keys, groups, err := s.groupRecords(ship, loc, records, startTime, endTime, dimension)
+	if err != nil {
+		logger.Logger.Errorf("[%s]%v", shipName, err)
+		return nil, err
+	}
+
+	stats := []*StateStat{}
+	for _, key := range keys {
+		byPeriod := make(map[string][]*record)
+		for _, r := range groups[key] {
+			period := periodOf(r, granularity)
+			byPeriod[period] = append(byPeriod[period], r)
+		}
+		for period, periodRecords := range byPeriod {
+			states := timeInState(periodRecords, loc, ship)
+			stat := &StateStat{Period: period, States: make(map[string]float64, len(states))}
+			for state, minutes := range states {
+				stat.States[state] = round(minutes)
+				stat.TotalDuration += minutes
+			}
+			if stat.TotalDuration <= 0 {
+				continue
+			}
+			stat.Utilization = round(states[StateDredging] / stat.TotalDuration * 100)
+			stat.TotalDuration = round(stat.TotalDuration)
+			if dimension == DimensionCrew {
+				stat.Crew = key
+			} else {
+				stat.ShiftName = key
+			}
+			stats = append(stats, stat)
+		}
+	}
+
+	order := make(map[string]int, len(keys))
+	for i, key := range keys {
+		order[key] = i
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		return order[a.ShiftName+a.Crew] < order[b.ShiftName+b.Crew]
+	})
+	return stats, nil
+}
+
+// GetDowntimeLog 返回某船 [startTime, endTime] 内非挖泥状态的连续区间，相同状态的相邻间隔合并，
+// 只返回不短于 minMinutes 分钟的区间；区间归属开始时所在的班次
+func (s *Service) GetDowntimeLog(shipName string, startTime, endTime int64, minMinutes float64) ([]*Downtime, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	loc := ShipLocation(shipName)
+
+	records, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询停工记录失败: %v", shipName, err)
+		return nil, err
+	}
+
+	maxGap := ship.Duration.maxGap()
+	downtimes := []*Downtime{}
+	var current *Downtime
+	flush := func() {
+		if current != nil && current.Duration >= minMinutes {
+			current.Duration = round(current.Duration)
+			downtimes = append(downtimes, current)
+		}
+		current = nil
+	}
+	for i := 1; i < len(records); i++ {
+		prev, cur := records[i-1], records[i]
+		state := StateNoData
+		if cur.RecordTime-prev.RecordTime <= maxGap {
+			state = ship.States.classify(prev, cur)
+		}
+		if current != nil && current.State != state {
+			flush()
+		}
+		if state == StateDredging {
+			continue
+		}
+		if current == nil {
+			slot := ship.shiftAt(time.UnixMilli(prev.RecordTime).In(loc))
+			current = &Downtime{
+				State:     state,
+				StateName: stateNames[state],
+				Start:     prev.RecordTime,
+				ShiftName: slot.Name,
+				ShiftDate: slot.Date,
+			}
+		}
+		current.End = cur.RecordTime
+		current.Duration = float64(current.End-current.Start) / float64(time.Minute/time.Millisecond)
+	}
+	flush()
+	return downtimes, nil
+}
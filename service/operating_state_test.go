@@ -0,0 +1,70 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	rule, err := ShipStateRule{}.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		prev record
+		cur  record
+		want string
+	}{
+		{name: "挖泥", prev: record{OutputRate: 800, PumpSpeed: 300, CutterSpeed: 20, TransverseSpeed: 10}, want: StateDredging},
+		{name: "挖泥优先于移船步进", prev: record{OutputRate: 800, PumpSpeed: 300}, cur: record{TrolleyTravel: 1}, want: StateDredging},
+		{name: "有产量但泥泵未转", prev: record{OutputRate: 800, CutterSpeed: 20}, want: StateOther},
+		{name: "移船步进", prev: record{TrolleyTravel: 2, PumpSpeed: 300}, cur: record{TrolleyTravel: 2.5}, want: StateSpudMoving},
+		{name: "台车行程变化低于阈值", prev: record{TrolleyTravel: 2}, cur: record{TrolleyTravel: 2.05}, want: StateStopped},
+		{name: "反向空横移", prev: record{TransverseSpeed: -3}, want: StateSwinging},
+		{name: "横移且绞刀转动但没有产量", prev: record{TransverseSpeed: 3, CutterSpeed: 20}, want: StateOther},
+		{name: "空泵", prev: record{PumpSpeed: 300, OutputRate: 5}, want: StateIdlePump},
+		{name: "停机", prev: record{}, want: StateStopped},
+		{name: "只有绞刀转动", prev: record{CutterSpeed: 20}, want: StateOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rule.classify(&tt.prev, &tt.cur); got != tt.want {
+				t.Errorf("classify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeInState(t *testing.T) {
+	const minute = int64(time.Minute / time.Millisecond)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC).UnixMilli()
+	ship := &ShipConfig{}
+	states, err := ship.States.normalize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ship.States = states
+
+	records := []*record{
+		{RecordTime: day, OutputRate: 800, PumpSpeed: 300},
+		{RecordTime: day + 2*minute, PumpSpeed: 300},
+		{RecordTime: day + 3*minute},
+		{RecordTime: day + 13*minute},
+		{RecordTime: day + 14*minute},
+	}
+	want := map[string]float64{StateDredging: 2, StateIdlePump: 1, StateNoData: 10, StateStopped: 1}
+
+	got := timeInState(records, time.UTC, ship)
+	if len(got) != len(want) {
+		t.Fatalf("timeInState() = %v, want %v", got, want)
+	}
+	for state, minutes := range want {
+		if math.Abs(got[state]-minutes) > 1e-9 {
+			t.Errorf("%s = %v 分钟, want %v", state, got[state], minutes)
+		}
+	}
+}
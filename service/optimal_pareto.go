@@ -0,0 +1,240 @@
+package service
+
+import (
+	"dredger/pkg/logger"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 多目标最优班组分析的目标
+const (
+	ObjectiveProduction   = "production"   // 产量，越大越好
+	ObjectiveUnitEnergy   = "unitEnergy"   // 单位产量能耗，越小越好
+	ObjectiveVacuumMargin = "vacuumMargin" // 允许的最大吸入真空度与平均估算真空度之差，越大越好
+)
+
+// ErrInvalidParetoOptions 多目标分析的目标、权重或约束有误
+var ErrInvalidParetoOptions = errors.New("多目标分析的选项有误")
+
+// objectiveMinimized 越小越好的目标
+var objectiveMinimized = map[string]bool{ObjectiveUnitEnergy: true}
+
+// normalize 补全默认目标和权重并校验选项
+func (o *ParetoOptions) normalize(hydraulics ShipHydraulicsConfig) error {
+	if len(o.Objectives) == 0 {
+		o.Objectives = []string{ObjectiveProduction, ObjectiveUnitEnergy}
+	}
+	seen := make(map[string]bool)
+	for i, objective := range o.Objectives {
+		objective = strings.TrimSpace(objective)
+		o.Objectives[i] = objective
+		switch objective {
+		case ObjectiveProduction, ObjectiveUnitEnergy, ObjectiveVacuumMargin:
+		default:
+			return fmt.Errorf("不支持的目标 %q，应为 %s、%s 或 %s", objective, ObjectiveProduction, ObjectiveUnitEnergy, ObjectiveVacuumMargin)
+		}
+		if seen[objective] {
+			return fmt.Errorf("目标 %s 重复", objective)
+		}
+		seen[objective] = true
+	}
+	for objective := range o.Weights {
+		if !seen[objective] {
+			return fmt.Errorf("目标 %s 不在比较的目标中，不能设置权重", objective)
+		}
+	}
+
+	weights := make(map[string]float64, len(o.Objectives))
+	var total float64
+	for _, objective := range o.Objectives {
+		weight, ok := o.Weights[objective]
+		if !ok {
+			weight = 1
+		}
+		if weight < 0 {
+			return fmt.Errorf("目标 %s 的权重不能为负数", objective)
+		}
+		weights[objective] = weight
+		total += weight
+	}
+	if total == 0 {
+		return errors.New("目标的权重不能全为 0")
+	}
+	o.Weights = weights
+
+	if o.MinProduction < 0 {
+		return errors.New("产量下限不能为负数")
+	}
+	if o.MaxVacuumKPa < 0 {
+		return errors.New("最大吸入真空度不能为负数")
+	}
+	if o.MaxVacuumKPa == 0 {
+		o.MaxVacuumKPa = hydraulics.MaxVacuumKPa
+	}
+	if seen[ObjectiveVacuumMargin] && o.MaxVacuumKPa == 0 {
+		return errors.New("船舶未登记允许的最大吸入真空度（maxVacuumKPa），无法计算真空余量")
+	}
+	return nil
+}
+
+// paretoCandidate 参与比较的班组及其各目标的值
+type paretoCandidate struct {
+	shift  *ParetoShift
+	values map[string]float64
+}
+
+// dominates 判断 a 是否支配 b：各目标都不比 b 差，且至少一个目标比 b 好
+func (a *paretoCandidate) dominates(b *paretoCandidate, objectives []string) bool {
+	better := false
+	for _, objective := range objectives {
+		x, y := a.values[objective], b.values[objective]
+		if objectiveMinimized[objective] {
+			x, y = -x, -y
+		}
+		if x < y {
+			return false
+		}
+		if x > y {
+			better = true
+		}
+	}
+	return better
+}
+
+// paretoFront 返回不被其它候选支配的候选，并按各目标在全部候选中的最小最大值归一化后加权打分
+func paretoFront(candidates []*paretoCandidate, opts ParetoOptions) []*ParetoShift {
+	var total float64
+	for _, objective := range opts.Objectives {
+		total += opts.Weights[objective]
+	}
+	for _, objective := range opts.Objectives {
+		lo, hi := candidates[0].values[objective], candidates[0].values[objective]
+		for _, c := range candidates {
+			lo = min(lo, c.values[objective])
+			hi = max(hi, c.values[objective])
+		}
+		for _, c := range candidates {
+			// 所有候选的值相同时，该目标不区分优劣，都记为 1
+			normalized := 1.0
+			if hi > lo {
+				normalized = (c.values[objective] - lo) / (hi - lo)
+				if objectiveMinimized[objective] {
+					normalized = 1 - normalized
+				}
+			}
+			c.shift.Normalized[objective] = round(normalized)
+			c.shift.Score += opts.Weights[objective] / total * normalized
+		}
+	}
+
+	front := []*ParetoShift{}
+	for _, c := range candidates {
+		dominated := false
+		for _, other := range candidates {
+			if other != c && other.dominates(c, opts.Objectives) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			c.shift.Score = round(c.shift.Score)
+			front = append(front, c.shift)
+		}
+	}
+	sort.SliceStable(front, func(i, j int) bool { return front[i].Score > front[j].Score })
+	return front
+}
+
+// GetParetoShifts 多目标的最优班组分析：按 groupBy 分组后（含义同 GetOptimalShift），找出产量、单位能耗（以及可选的真空余量）不被其它班组支配的班组，
+// 并给出加权得分。产量为 0 或低于下限的班组不参与比较，避免几乎没有施工的班组因能耗低而入选
+func (s *Service) GetParetoShifts(shipName string, startTime, endTime int64, dimension, groupBy string, opts ParetoOptions) (*ParetoShiftResponse, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	if err = opts.normalize(ship.Hydraulics); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParetoOptions, err)
+	}
+	loc := ShipLocation(shipName)
+
+	response := &ParetoShiftResponse{
+		Objectives:   opts.Objectives,
+		Weights:      opts.Weights,
+		EnergyUnit:   ship.Energy.energyUnit(),
+		ParetoBySoil: make(map[string]*ParetoFront),
+	}
+	allRecords, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询最优班组数据失败: %v", shipName, err)
+		return nil, err
+	}
+	if len(allRecords) == 0 {
+		return response, nil
+	}
+	recordsBySoil, err := s.recordsBySoil(ship, allRecords, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for soilType, records := range recordsBySoil {
+		front := &ParetoFront{Front: []*ParetoShift{}}
+		keys, groups, err := s.groupRecords(ship, loc, records, startTime, endTime, dimension)
+		if err != nil {
+			logger.Logger.Errorf("[%s]%v", shipName, err)
+			return nil, err
+		}
+
+		var candidates []*paretoCandidate
+		for _, key := range keys {
+			shiftRecords := groups[key]
+			work := measureWorkload(shiftRecords, loc, ship.Duration)
+			if work.Duration <= 0 {
+				continue
+			}
+			if work.Production <= 0 || work.Production < opts.MinProduction {
+				front.Excluded++
+				continue
+			}
+
+			params, optimalTime := calParams(shiftRecords, ship.Hydraulics)
+			shift := &ParetoShift{
+				Production:  round(work.Production),
+				UnitEnergy:  round(work.UnitEnergy()),
+				Normalized:  make(map[string]float64, len(opts.Objectives)),
+				OptimalTime: optimalTime,
+				Parameters:  params,
+			}
+			if dimension == DimensionCrew {
+				shift.Crew = key
+			} else {
+				shift.ShiftName = key
+			}
+			values := map[string]float64{
+				ObjectiveProduction: work.Production,
+				ObjectiveUnitEnergy: work.UnitEnergy(),
+			}
+			if _, ok := opts.Weights[ObjectiveVacuumMargin]; ok {
+				avg, ok := averageVacuum(shiftRecords, ship.Hydraulics)
+				if !ok {
+					// 无法估算真空度的班组不参与真空余量的比较
+					front.Excluded++
+					continue
+				}
+				values[ObjectiveVacuumMargin] = opts.MaxVacuumKPa - avg
+				margin := round(opts.MaxVacuumKPa - avg)
+				shift.VacuumMargin = &margin
+			}
+			candidates = append(candidates, &paretoCandidate{shift: shift, values: values})
+		}
+
+		front.Candidates = len(candidates)
+		if len(candidates) > 0 {
+			front.Front = paretoFront(candidates, opts)
+			front.Recommended = front.Front[0]
+		}
+		response.ParetoBySoil[soilType] = front
+	}
+	return response, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParetoFront(t *testing.T) {
+	type candidate struct {
+		name       string
+		production float64
+		unitEnergy float64
+	}
+	tests := []struct {
+		name       string
+		candidates []candidate
+		weights    map[string]float64
+		want       []string
+		wantScores []float64
+	}{
+		{
+			name: "被支配的班组不在前沿中",
+			candidates: []candidate{
+				{"0-6", 1000, 2},
+				{"6-12", 800, 1.5},
+				{"12-18", 700, 2.5}, // 产量更低、能耗更高，被 0-6 支配
+			},
+			want:       []string{"0-6", "6-12"},
+			wantScores: []float64{0.75, 0.67},
+		},
+		{
+			name: "加权后排序改变",
+			candidates: []candidate{
+				{"0-6", 1000, 2},
+				{"6-12", 800, 1.5},
+				{"12-18", 700, 2.5},
+			},
+			weights:    map[string]float64{ObjectiveUnitEnergy: 2},
+			want:       []string{"6-12", "0-6"},
+			wantScores: []float64{0.78, 0.67},
+		},
+		{
+			name: "相同的班组互不支配",
+			candidates: []candidate{
+				{"0-6", 1000, 2},
+				{"6-12", 1000, 2},
+			},
+			want:       []string{"0-6", "6-12"},
+			wantScores: []float64{1, 1},
+		},
+		{
+			name: "一个目标相同时另一个目标决定支配",
+			candidates: []candidate{
+				{"0-6", 1000, 2},
+				{"6-12", 1000, 1.8},
+			},
+			want:       []string{"6-12"},
+			wantScores: []float64{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ParetoOptions{Weights: tt.weights}
+			if err := opts.normalize(ShipHydraulicsConfig{}); err != nil {
+				t.Fatal(err)
+			}
+			var candidates []*paretoCandidate
+			for _, c := range tt.candidates {
+				candidates = append(candidates, &paretoCandidate{
+					shift: &ParetoShift{ShiftName: c.name, Normalized: make(map[string]float64)},
+					values: map[string]float64{
+						ObjectiveProduction: c.production,
+						ObjectiveUnitEnergy: c.unitEnergy,
+					},
+				})
+			}
+
+			front := paretoFront(candidates, opts)
+			var names []string
+			var scores []float64
+			for _, shift := range front {
+				names = append(names, shift.ShiftName)
+				scores = append(scores, shift.Score)
+			}
+			if !reflect.DeepEqual(names, tt.want) || !reflect.DeepEqual(scores, tt.wantScores) {
+				t.Errorf("paretoFront() = %v %v, want %v %v", names, scores, tt.want, tt.wantScores)
+			}
+		})
+	}
+}
+
+func TestParetoOptionsNormalizeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ParetoOptions
+	}{
+		{name: "未知目标", opts: ParetoOptions{Objectives: []string{"speed"}}},
+		{name: "重复目标", opts: ParetoOptions{Objectives: []string{ObjectiveProduction, ObjectiveProduction}}},
+		{name: "权重的目标不参与比较", opts: ParetoOptions{Objectives: []string{ObjectiveProduction}, Weights: map[string]float64{ObjectiveUnitEnergy: 1}}},
+		{name: "权重全为 0", opts: ParetoOptions{Weights: map[string]float64{ObjectiveProduction: 0, ObjectiveUnitEnergy: 0}}},
+		{name: "未登记最大吸入真空度", opts: ParetoOptions{Objectives: []string{ObjectiveProduction, ObjectiveVacuumMargin}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.normalize(ShipHydraulicsConfig{}); err == nil {
+				t.Error("normalize() 应返回错误")
+			}
+		})
+	}
+}
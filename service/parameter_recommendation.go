@@ -0,0 +1,295 @@
+package service
+
+import (
+	"dredger/pkg/logger"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// 施工参数推荐选项的默认值
+const (
+	defaultRecommendBins       = 10
+	defaultRecommendMinSamples = 30
+	defaultRecommendTolerance  = 0.1
+)
+
+// recommendParameters 参与推荐的施工参数，名称与 ParameterStats 的字段名相同
+var recommendParameters = []struct {
+	name  string
+	label string
+	value func(r *record) float64
+}{
+	{"flow", "流量", func(r *record) float64 { return r.FlowRate }},
+	{"concentration", "浓度", func(r *record) float64 { return r.Concentration }},
+	{"sPumpRpm", "水下泵转速", func(r *record) float64 { return r.PumpSpeed }},
+	{"cutterDepth", "绞刀深度", func(r *record) float64 { return -r.CutterDepth }},
+	{"carriageTravel", "台车行程", func(r *record) float64 { return r.TrolleyTravel }},
+	{"horizontalSpeed", "横移速度", func(r *record) float64 { return r.TransverseSpeed }},
+}
+
+func (o *RecommendOptions) normalize() error {
+	if o.Bins < 0 || o.MinSamples < 0 || o.Tolerance < 0 {
+		return errors.New("区间数、最少样本数和容差不能为负数")
+	}
+	if o.Bins == 0 {
+		o.Bins = defaultRecommendBins
+	}
+	if o.MinSamples == 0 {
+		o.MinSamples = defaultRecommendMinSamples
+	}
+	if o.Tolerance == 0 {
+		o.Tolerance = defaultRecommendTolerance
+	}
+	if o.Bins < 2 {
+		return errors.New("区间数至少为 2")
+	}
+	return nil
+}
+
+// workSample 一个施工采样间隔：取间隔起点的参数值，产量率和功率取两端的平均值
+type workSample struct {
+	rec   *record
+	hours float64
+	rate  float64
+	power float64
+}
+
+// workSamples 返回记录中的施工采样间隔，数据中断和未达到施工条件的间隔不计入，与 measureWorkload 一致
+func workSamples(records []*record, loc *time.Location, rule ShipDurationRule) []workSample {
+	var samples []workSample
+	maxGap := rule.maxGap()
+	eachInterval(records, loc, func(prev, cur *record) {
+		interval := cur.RecordTime - prev.RecordTime
+		if interval > maxGap || !rule.working(prev) {
+			return
+		}
+		samples = append(samples, workSample{
+			rec:   prev,
+			hours: float64(interval) / float64(time.Hour/time.Millisecond),
+			rate:  (prev.OutputRate + cur.OutputRate) / 2,
+			power: (prev.Power + cur.Power) / 2,
+		})
+	})
+	return samples
+}
+
+// sampleGroup 一组样本的时间加权产量、能耗和逐样本的产量率、单位能耗，用于比较均值
+type sampleGroup struct {
+	hours, production, energy float64
+	rates, unitEnergies       []float64
+}
+
+func (g *sampleGroup) add(s workSample) {
+	g.hours += s.hours
+	g.production += s.rate * s.hours
+	g.energy += s.power * s.hours
+	g.rates = append(g.rates, s.rate)
+	if s.rate > 0 {
+		g.unitEnergies = append(g.unitEnergies, s.power/s.rate)
+	}
+}
+
+func (g *sampleGroup) productionRate() float64 {
+	if g.hours > 0 {
+		return g.production / g.hours
+	}
+	return 0
+}
+
+func (g *sampleGroup) unitEnergy() float64 {
+	if g.production > 0 {
+		return g.energy / g.production
+	}
+	return 0
+}
+
+// quantile 返回升序数据的 q 分位数，相邻两点线性插值
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
+
+// confidence 按 Welch t 检验给出 a 的均值大于 b 的单侧置信度，任一组少于 2 个样本时返回 0
+func confidence(a, b []float64) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	meanVar := func(data []float64) (mean, variance float64) {
+		for _, v := range data {
+			mean += v
+		}
+		mean /= float64(len(data))
+		for _, v := range data {
+			variance += (v - mean) * (v - mean)
+		}
+		return mean, variance / float64(len(data)-1)
+	}
+	ma, va := meanVar(a)
+	mb, vb := meanVar(b)
+	se := math.Sqrt(va/float64(len(a)) + vb/float64(len(b)))
+	if se == 0 {
+		if ma > mb {
+			return 1
+		}
+		return 0
+	}
+	// 样本量较大，用正态分布近似 t 分布
+	return round(0.5 * (1 + math.Erf((ma-mb)/se/math.Sqrt2)))
+}
+
+// recommendParameter 在 5%~95% 分位数之间等宽分区，统计各区间的产量率和单位能耗，
+// 以样本足够的最优区间为中心，把与最优值相差不超过容差的相邻区间合并为推荐范围
+func recommendParameter(samples []workSample, value func(r *record) float64, opts RecommendOptions) *ParameterRecommendation {
+	rec := &ParameterRecommendation{Bins: []*ParameterBin{}}
+	values := make([]float64, len(samples))
+	for i, s := range samples {
+		values[i] = value(s.rec)
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	lo, hi := quantile(sorted, 0.05), quantile(sorted, 0.95)
+	if hi <= lo {
+		return rec
+	}
+
+	width := (hi - lo) / float64(opts.Bins)
+	groups := make([]sampleGroup, opts.Bins)
+	index := make([]int, len(samples)) // 各样本所在区间，-1 为离群值
+	for i, s := range samples {
+		v := values[i]
+		if v < lo || v > hi {
+			index[i] = -1
+			rec.Outliers++
+			continue
+		}
+		bin := min(int((v-lo)/width), opts.Bins-1)
+		index[i] = bin
+		groups[bin].add(s)
+	}
+	for i := range groups {
+		rec.Bins = append(rec.Bins, &ParameterBin{
+			Min:            round(lo + float64(i)*width),
+			Max:            round(lo + float64(i+1)*width),
+			SampleCount:    len(groups[i].rates),
+			ProductionRate: round(groups[i].productionRate()),
+			UnitEnergy:     round(groups[i].unitEnergy()),
+		})
+	}
+
+	// pick 以 metric 最优的区间为中心向两侧扩展，返回推荐范围的首尾区间
+	pick := func(metric func(g *sampleGroup) float64, better func(a, b float64) bool) (int, int, bool) {
+		eligible := func(i int) bool { return len(groups[i].rates) >= opts.MinSamples && groups[i].production > 0 }
+		best := -1
+		for i := range groups {
+			if eligible(i) && (best < 0 || better(metric(&groups[i]), metric(&groups[best]))) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return 0, 0, false
+		}
+		target := metric(&groups[best])
+		near := func(i int) bool {
+			return eligible(i) && math.Abs(metric(&groups[i])-target) <= opts.Tolerance*math.Abs(target)
+		}
+		first, last := best, best
+		for first > 0 && near(first-1) {
+			first--
+		}
+		for last < len(groups)-1 && near(last+1) {
+			last++
+		}
+		return first, last, true
+	}
+	// newRange 汇总推荐范围内的样本，按范围内外的差异计算置信度
+	newRange := func(first, last int, byEnergy bool) *RecommendedRange {
+		var in, out sampleGroup
+		for i, s := range samples {
+			if index[i] >= first && index[i] <= last {
+				in.add(s)
+			} else if index[i] >= 0 {
+				out.add(s)
+			}
+		}
+		r := &RecommendedRange{
+			Min:            rec.Bins[first].Min,
+			Max:            rec.Bins[last].Max,
+			SampleCount:    len(in.rates),
+			ProductionRate: round(in.productionRate()),
+			UnitEnergy:     round(in.unitEnergy()),
+		}
+		if byEnergy {
+			r.Confidence = confidence(out.unitEnergies, in.unitEnergies)
+		} else {
+			r.Confidence = confidence(in.rates, out.rates)
+		}
+		return r
+	}
+
+	if first, last, ok := pick((*sampleGroup).productionRate, func(a, b float64) bool { return a > b }); ok {
+		rec.MaxProduction = newRange(first, last, false)
+	}
+	if first, last, ok := pick((*sampleGroup).unitEnergy, func(a, b float64) bool { return a < b }); ok {
+		rec.MinUnitEnergy = newRange(first, last, true)
+	}
+	return rec
+}
+
+// GetParameterRecommendations 按土质统计 [startTime, endTime] 内的施工采样间隔，对流量、浓度、泵转速、绞刀深度、
+// 台车行程和横移速度分区间比较产量率和单位能耗，给出推荐的施工参数范围、置信度和样本数
+func (s *Service) GetParameterRecommendations(shipName string, startTime, endTime int64, opts RecommendOptions) (*ParameterRecommendationResponse, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	if err = opts.normalize(); err != nil {
+		return nil, err
+	}
+	loc := ShipLocation(shipName)
+
+	response := &ParameterRecommendationResponse{
+		Bins:       opts.Bins,
+		MinSamples: opts.MinSamples,
+		Tolerance:  opts.Tolerance,
+		EnergyUnit: ship.Energy.energyUnit(),
+		BySoil:     make(map[string]*SoilRecommendation),
+	}
+	allRecords, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询参数推荐数据失败: %v", shipName, err)
+		return nil, err
+	}
+	if len(allRecords) == 0 {
+		return response, nil
+	}
+	recordsBySoil, err := s.recordsBySoil(ship, allRecords, opts.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	for soilType, records := range recordsBySoil {
+		samples := workSamples(records, loc, ship.Duration)
+		if len(samples) == 0 {
+			continue
+		}
+		soil := &SoilRecommendation{SampleCount: len(samples)}
+		var hours float64
+		for _, sample := range samples {
+			hours += sample.hours
+		}
+		soil.WorkDuration = round(hours * 60)
+		for _, p := range recommendParameters {
+			rec := recommendParameter(samples, p.value, opts)
+			rec.Name, rec.Label = p.name, p.label
+			soil.Parameters = append(soil.Parameters, rec)
+		}
+		response.BySoil[soilType] = soil
+	}
+	return response, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	tests := []struct {
+		sorted []float64
+		q      float64
+		want   float64
+	}{
+		{sorted: []float64{1, 2, 3, 4, 5}, q: 0, want: 1},
+		{sorted: []float64{1, 2, 3, 4, 5}, q: 0.05, want: 1.2},
+		{sorted: []float64{1, 2, 3, 4, 5}, q: 0.5, want: 3},
+		{sorted: []float64{1, 2, 3, 4, 5}, q: 0.95, want: 4.8},
+		{sorted: []float64{1, 2, 3, 4, 5}, q: 1, want: 5},
+		{sorted: []float64{7}, q: 0.5, want: 7},
+	}
+
+	for _, tt := range tests {
+		if got := quantile(tt.sorted, tt.q); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("quantile(%v, %v) = %v, want %v", tt.sorted, tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{name: "样本不足", a: []float64{10}, b: []float64{1, 2}, want: 0},
+		{name: "无方差且 a 更大", a: []float64{5, 5}, b: []float64{3, 3}, want: 1},
+		{name: "无方差且相等", a: []float64{5, 5}, b: []float64{5, 5}, want: 0},
+		{name: "均值相同", a: []float64{1, 3}, b: []float64{3, 1}, want: 0.5},
+		{name: "a 明显更大", a: []float64{10, 11, 12, 10, 11, 12}, b: []float64{1, 2, 3, 1, 2, 3}, want: 1},
+		{name: "a 明显更小", a: []float64{1, 2, 3, 1, 2, 3}, b: []float64{10, 11, 12, 10, 11, 12}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := confidence(tt.a, tt.b); got != tt.want {
+				t.Errorf("confidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecommendParameter(t *testing.T) {
+	// 参数值 0~20 各一小时：低于 10 时产量率 100、功率 200，否则产量率 50、功率 50。
+	// 5%~95% 分位数为 [1, 19]，0 和 20 为离群值
+	var samples []workSample
+	for v := 0; v <= 20; v++ {
+		s := workSample{rec: &record{FlowRate: float64(v)}, hours: 1, rate: 50, power: 50}
+		if v < 10 {
+			s.rate, s.power = 100, 200
+		}
+		samples = append(samples, s)
+	}
+	bins := []*ParameterBin{
+		{Min: 1, Max: 10, SampleCount: 9, ProductionRate: 100, UnitEnergy: 2},
+		{Min: 10, Max: 19, SampleCount: 10, ProductionRate: 50, UnitEnergy: 1},
+	}
+	lowRange := &RecommendedRange{Min: 1, Max: 10, SampleCount: 9, ProductionRate: 100, UnitEnergy: 2, Confidence: 1}
+	highRange := &RecommendedRange{Min: 10, Max: 19, SampleCount: 10, ProductionRate: 50, UnitEnergy: 1, Confidence: 1}
+	wholeRange := &RecommendedRange{Min: 1, Max: 19, SampleCount: 19, ProductionRate: 73.68, UnitEnergy: 1.64}
+
+	tests := []struct {
+		name    string
+		samples []workSample
+		opts    RecommendOptions
+		want    *ParameterRecommendation
+	}{
+		{
+			name:    "产量率和单位能耗的最优区间不同",
+			samples: samples,
+			opts:    RecommendOptions{Bins: 2, MinSamples: 1, Tolerance: 0.1},
+			want:    &ParameterRecommendation{Bins: bins, Outliers: 2, MaxProduction: lowRange, MinUnitEnergy: highRange},
+		},
+		{
+			name:    "样本不足的区间不参与推荐",
+			samples: samples,
+			opts:    RecommendOptions{Bins: 2, MinSamples: 10, Tolerance: 0.1},
+			want: &ParameterRecommendation{
+				Bins:          bins,
+				Outliers:      2,
+				MaxProduction: &RecommendedRange{Min: 10, Max: 19, SampleCount: 10, ProductionRate: 50, UnitEnergy: 1},
+				MinUnitEnergy: highRange,
+			},
+		},
+		{
+			name:    "容差内的相邻区间合并",
+			samples: samples,
+			opts:    RecommendOptions{Bins: 2, MinSamples: 1, Tolerance: 1},
+			want:    &ParameterRecommendation{Bins: bins, Outliers: 2, MaxProduction: wholeRange, MinUnitEnergy: wholeRange},
+		},
+		{
+			name:    "参数值没有变化",
+			samples: []workSample{{rec: &record{FlowRate: 5}, hours: 1, rate: 100}, {rec: &record{FlowRate: 5}, hours: 1, rate: 100}},
+			opts:    RecommendOptions{Bins: 2, MinSamples: 1, Tolerance: 0.1},
+			want:    &ParameterRecommendation{Bins: []*ParameterBin{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := recommendParameter(tt.samples, func(r *record) float64 { return r.FlowRate }, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recommendParameter() = %s, want %s", formatRecommendation(got), formatRecommendation(tt.want))
+			}
+		})
+	}
+}
+
+func formatRecommendation(r *ParameterRecommendation) string {
+	s := ""
+	for _, bin := range r.Bins {
+		s += fmt.Sprintf("%+v ", *bin)
+	}
+	return fmt.Sprintf("bins %s outliers %d maxProduction %+v minUnitEnergy %+v", s, r.Outliers, r.MaxProduction, r.MinUnitEnergy)
+}
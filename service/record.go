@@ -0,0 +1,246 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// record 施工记录的统一视图。两种数据结构的列名和含义不同（如桥架深度与绞刀深度、小时产量率与当班产量率），
+// 读取时按船舶的字段映射转换为统一视图，班组统计、最优班组、饼图和回放都只使用这里的字段
+type record struct {
+	ShipName   string
+	RecordTime int64
+	ShiftDate  string // 所在班次开始的日期（船舶时区），由 groupByShift 填写
+
+	OutputRate        float64 // 产量率 m³/h
+	Power             float64 // 泵功率 kW，由能耗模型计算
+	TransverseSpeed   float64 // 横移速度
+	TrolleyTravel     float64 // 台车行程
+	CutterDepth       float64 // 绞刀（桥架）深度，向下为正
+	PumpSpeed         float64 // 水下泵转速
+	CutterSpeed       float64 // 绞刀转速
+	Concentration     float64 // 浓度
+	FlowRate          float64 // 流量
+	FlowVelocity      float64 // 流速
+	DischargePressure float64 // 排压，参数统计中的“增压泵排压”
+	FuelRate          float64 // 油耗率 L/h
+	SuctionVacuum     float64 // 实测吸入真空度
+	CutterX           float64
+	CutterY           float64
+
+	// 以下用于估算吸入真空度
+	WaterDensity        float64
+	Density             float64
+	FieldSlurryDensity  float64
+	MudPipeDiameter     float64
+	EarDraft            float64
+	LeftEarDraft        float64
+	RightEarDraft       float64
+	EarToBottomDistance float64
+}
+
+// solidsRate 按流量（m³/h）和体积浓度（%）估算的产量率 m³/h
+func (r *record) solidsRate() float64 {
+	return r.FlowRate * r.Concentration / 100
+}
+
+// RecordField 统一视图中一个字段的取值方式
+type RecordField struct {
+	Columns []string `json:"columns"`           // 数据库列名
+	Sum     bool     `json:"sum,omitempty"`     // 为 true 时各列求和，否则取第一个非零的列
+	Default float64  `json:"default,omitempty"` // 各列均为 0 或未配置列时使用的值
+}
+
+// recordFields 统一视图中可配置的字段，键为 ShipConfig.Fields 中使用的名称
+var recordFields = map[string]func(r *record) *float64{
+	"outputRate":          func(r *record) *float64 { return &r.OutputRate },
+	"transverseSpeed":     func(r *record) *float64 { return &r.TransverseSpeed },
+	"trolleyTravel":       func(r *record) *float64 { return &r.TrolleyTravel },
+	"cutterDepth":         func(r *record) *float64 { return &r.CutterDepth },
+	"pumpSpeed":           func(r *record) *float64 { return &r.PumpSpeed },
+	"cutterSpeed":         func(r *record) *float64 { return &r.CutterSpeed },
+	"concentration":       func(r *record) *float64 { return &r.Concentration },
+	"flowRate":            func(r *record) *float64 { return &r.FlowRate },
+	"flowVelocity":        func(r *record) *float64 { return &r.FlowVelocity },
+	"dischargePressure":   func(r *record) *float64 { return &r.DischargePressure },
+	"suctionVacuum":       func(r *record) *float64 { return &r.SuctionVacuum },
+	"cutterX":             func(r *record) *float64 { return &r.CutterX },
+	"cutterY":             func(r *record) *float64 { return &r.CutterY },
+	"waterDensity":        func(r *record) *float64 { return &r.WaterDensity },
+	"density":             func(r *record) *float64 { return &r.Density },
+	"fieldSlurryDensity":  func(r *record) *float64 { return &r.FieldSlurryDensity },
+	"mudPipeDiameter":     func(r *record) *float64 { return &r.MudPipeDiameter },
+	"earDraft":            func(r *record) *float64 { return &r.EarDraft },
+	"leftEarDraft":        func(r *record) *float64 { return &r.LeftEarDraft },
+	"rightEarDraft":       func(r *record) *float64 { return &r.RightEarDraft },
+	"earToBottomDistance": func(r *record) *float64 { return &r.EarToBottomDistance },
+}
+
+// 两种数据结构中列名相同的字段
+var commonRecordFields = map[string]RecordField{
+	"transverseSpeed":    {Columns: []string{"transverse_speed"}},
+	"trolleyTravel":      {Columns: []string{"trolley_travel"}},
+	"pumpSpeed":          {Columns: []string{"underwater_pump_speed"}},
+	"cutterSpeed":        {Columns: []string{"cutter_speed"}},
+	"concentration":      {Columns: []string{"concentration"}},
+	"flowRate":           {Columns: []string{"flow_rate"}},
+	"flowVelocity":       {Columns: []string{"flow_velocity"}},
+	"suctionVacuum":      {Columns: []string{"underwater_pump_suction_vacuum"}},
+	"cutterX":            {Columns: []string{"cutter_x"}},
+	"cutterY":            {Columns: []string{"cutter_y"}},
+	"waterDensity":       {Columns: []string{"water_density"}},
+	"density":            {Columns: []string{"density"}},
+	"fieldSlurryDensity": {Columns: []string{"field_slurry_density"}},
+	"earDraft":           {Columns: []string{"ear_draft"}},
+	"leftEarDraft":       {Columns: []string{"left_ear_draft"}},
+	"rightEarDraft":      {Columns: []string{"right_ear_draft"}},
+}
+
+// schemaRecordFields 各数据结构特有的字段映射；功率和油耗率由能耗模型计算，不在这里映射
+var schemaRecordFields = map[string]map[string]RecordField{
+	DataSchemaHL: {
+		"outputRate":        {Columns: []string{"hourly_output_rate"}},
+		"cutterDepth":       {Columns: []string{"bridge_depth"}},
+		"dischargePressure": {Columns: []string{"mud_pump_2_discharge_pressure", "mud_pump_1_discharge_pressure", "underwater_pump_discharge_pressure"}},
+		// hl 数据结构没有泥管直径和耳轴到船底距离，使用敏龙的泥管直径，以及按敏龙的比例折算的耳轴到船底距离
+		"mudPipeDiameter":     {Default: 0.7},
+		"earToBottomDistance": {Default: 12.9},
+	},
+	DataSchemaML: {
+		"outputRate":          {Columns: []string{"current_shift_output_rate"}},
+		"cutterDepth":         {Columns: []string{"cutter_depth"}},
+		"dischargePressure":   {Columns: []string{"booster_pump_discharge_pressure"}},
+		"mudPipeDiameter":     {Columns: []string{"mud_pipe_diameter"}},
+		"earToBottomDistance": {Columns: []string{"ear_to_bottom_distance"}},
+	},
+}
+
+// mappedField 已解析为模型字段下标的字段映射
+type mappedField struct {
+	target func(r *record) *float64
+	fields []int
+	RecordField
+}
+
+// recordView 把某种施工数据模型转换为统一视图
+type recordView struct {
+	modelType reflect.Type
+	columns   []string // 需要查询的列
+	fields    []mappedField
+	mapped    map[string]RecordField // 生效的字段映射
+	energy    *energyView
+}
+
+// newRecordView 按数据结构的默认映射和 overrides 建立统一视图，功率和油耗率按能耗模型计算
+func newRecordView(modelType reflect.Type, schema string, overrides map[string]RecordField, energy ShipEnergyModel) (*recordView, error) {
+	mapped := make(map[string]RecordField)
+	for name, f := range commonRecordFields {
+		mapped[name] = f
+	}
+	for name, f := range schemaRecordFields[schema] {
+		mapped[name] = f
+	}
+	for name, f := range overrides {
+		if _, ok := recordFields[name]; !ok {
+			return nil, fmt.Errorf("未知的字段映射 %s", name)
+		}
+		mapped[name] = f
+	}
+
+	available := floatColumns(modelType)
+	view := &recordView{modelType: modelType, columns: []string{"ship_name", "record_time"}, mapped: mapped}
+	seen := make(map[string]bool)
+	names := make([]string, 0, len(mapped))
+	for name := range mapped {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := mapped[name]
+		m := mappedField{target: recordFields[name], RecordField: f}
+		for _, column := range f.Columns {
+			index, ok := available[column]
+			if !ok {
+				return nil, fmt.Errorf("字段 %s 映射的列 %s 不是 %s 数据结构的数值列", name, column, schema)
+			}
+			m.fields = append(m.fields, index)
+			if !seen[column] {
+				seen[column] = true
+				view.columns = append(view.columns, column)
+			}
+		}
+		view.fields = append(view.fields, m)
+	}
+
+	energyView, columns, err := newEnergyView(modelType, energy, available)
+	if err != nil {
+		return nil, err
+	}
+	view.energy = energyView
+	for _, column := range columns {
+		if !seen[column] {
+			seen[column] = true
+			view.columns = append(view.columns, column)
+		}
+	}
+	return view, nil
+}
+
+// column 返回统一视图字段映射的第一列，未映射时返回空
+func (v *recordView) column(name string) string {
+	if columns := v.mapped[name].Columns; len(columns) > 0 {
+		return columns[0]
+	}
+	return ""
+}
+
+// convert 把施工数据模型（结构体值）转换为统一视图
+func (v *recordView) convert(src reflect.Value) *record {
+	r := &record{
+		ShipName:   src.FieldByName("ShipName").String(),
+		RecordTime: src.FieldByName("RecordTime").Int(),
+	}
+	for _, f := range v.fields {
+		var value float64
+		for _, i := range f.fields {
+			x := src.Field(i).Float()
+			if f.Sum {
+				value += x
+			} else if x != 0 {
+				value = x
+				break
+			}
+		}
+		if value == 0 {
+			value = f.Default
+		}
+		*f.target(r) = value
+	}
+	v.energy.apply(src, r)
+	return r
+}
+
+// loadRecords 按统一视图读取某船 [startTime, endTime] 内的记录，按时间升序排列
+func loadRecords(db *gorm.DB, ship *ShipConfig, startTime, endTime int64) ([]*record, error) {
+	view := ship.view
+	list := reflect.New(reflect.SliceOf(reflect.PointerTo(view.modelType)))
+	err := db.Model(ship.dataModel()).
+		Select(view.columns).
+		Where("ship_name = ?", ship.Name).
+		Where("record_time BETWEEN ? AND ?", startTime, endTime).
+		Order("record_time ASC").
+		Find(list.Interface()).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rows := list.Elem()
+	records := make([]*record, rows.Len())
+	for i := range records {
+		records[i] = view.convert(rows.Index(i).Elem())
+	}
+	return records, nil
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"dredger/model"
+)
+
+func TestRecordViewConvert(t *testing.T) {
+	tests := []struct {
+		name      string
+		schema    string
+		overrides map[string]RecordField
+		src       any
+		want      record
+	}{
+		{
+			name:   "hl 取第一个非零的排压，缺少的列使用默认值",
+			schema: DataSchemaHL,
+			src: model.DredgerDataHl{
+				ShipName:                        "华安龙",
+				RecordTime:                      1000,
+				HourlyOutputRate:                1200,
+				BridgeDepth:                     8.5,
+				MudPump1DischargePressure:       3.2,
+				UnderwaterPumpDischargePressure: 1.1,
+			},
+			want: record{
+				ShipName:            "华安龙",
+				RecordTime:          1000,
+				OutputRate:          1200,
+				CutterDepth:         8.5,
+				DischargePressure:   3.2,
+				MudPipeDiameter:     0.7,
+				EarToBottomDistance: 12.9,
+			},
+		},
+		{
+			name:   "ml 使用当班产量率和绞刀深度",
+			schema: DataSchemaML,
+			src: model.DredgerDatum{
+				ShipName:                     "敏龙",
+				RecordTime:                   2000,
+				CurrentShiftOutputRate:       900,
+				CutterDepth:                  6,
+				BoosterPumpDischargePressure: 2.5,
+				MudPipeDiameter:              0.8,
+				TransverseSpeed:              12,
+			},
+			want: record{
+				ShipName:          "敏龙",
+				RecordTime:        2000,
+				OutputRate:        900,
+				CutterDepth:       6,
+				DischargePressure: 2.5,
+				MudPipeDiameter:   0.8,
+				TransverseSpeed:   12,
+			},
+		},
+		{
+			name:   "覆盖映射并对各列求和",
+			schema: DataSchemaHL,
+			overrides: map[string]RecordField{
+				"transverseSpeed": {Columns: []string{"left_transverse_speed", "right_transverse_speed"}, Sum: true},
+				"cutterDepth":     {Columns: []string{"bridge_depth"}, Default: 5},
+			},
+			src: model.DredgerDataHl{
+				ShipName:             "华安龙",
+				RecordTime:           3000,
+				TransverseSpeed:      99,
+				LeftTransverseSpeed:  4,
+				RightTransverseSpeed: 6,
+			},
+			want: record{
+				ShipName:            "华安龙",
+				RecordTime:          3000,
+				TransverseSpeed:     10,
+				CutterDepth:         5,
+				MudPipeDiameter:     0.7,
+				EarToBottomDistance: 12.9,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			energy := ShipEnergyModel{}
+			if err := energy.normalize(tt.schema); err != nil {
+				t.Fatal(err)
+			}
+			view, err := newRecordView(reflect.TypeOf(tt.src), tt.schema, tt.overrides, energy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := view.convert(reflect.ValueOf(tt.src))
+			// 功率和油耗率由能耗模型计算，这里只比较字段映射
+			got.Power, got.FuelRate = 0, 0
+			if *got != tt.want {
+				t.Errorf("convert() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRecordViewInvalidMapping(t *testing.T) {
+	tests := []struct {
+		name      string
+		overrides map[string]RecordField
+	}{
+		{name: "未知字段", overrides: map[string]RecordField{"power": {Columns: []string{"mud_pump_1_power"}}}},
+		{name: "不存在的列", overrides: map[string]RecordField{"cutterDepth": {Columns: []string{"cutter_depth"}}}},
+		{name: "非数值列", overrides: map[string]RecordField{"cutterDepth": {Columns: []string{"ship_name"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			energy := ShipEnergyModel{}
+			if err := energy.normalize(DataSchemaHL); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := newRecordView(reflect.TypeOf(model.DredgerDataHl{}), DataSchemaHL, tt.overrides, energy); err == nil {
+				t.Error("newRecordView() 应返回错误")
+			}
+		})
+	}
+}
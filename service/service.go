@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"dredger/pkg/conf"
 	"dredger/pkg/logger"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,7 +23,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm/clause"
 
 	"dredger/model"
@@ -38,6 +40,10 @@ type Service struct {
 	demoDirs map[DemoID]string              // 1..6 => ./pys/demoN
 	seen     map[DemoID]map[string]struct{} // 已知文件名
 	mu       sync.Mutex
+
+	jobs        map[int64]*importJobRun // 运行中或排队中的导入任务
+	jobMu       sync.Mutex
+	importSlots chan struct{} // 同时执行的导入任务数上限
 }
 
 func exeBaseDir() string {
@@ -86,8 +92,11 @@ func NewService(db *gorm.DB) *Service {
 			Demo5: filepath.Join(pysBase, "demo5"),
 			Demo6: filepath.Join(pysBase, "demo6"),
 		},
-		seen: make(map[DemoID]map[string]struct{}),
+		seen:        make(map[DemoID]map[string]struct{}),
+		jobs:        make(map[int64]*importJobRun),
+		importSlots: make(chan struct{}, 1),
 	}
+	registry.bind(db)
 	s.initDemoSeen()
 	return s
 }
@@ -107,375 +116,370 @@ func (s *Service) initDemoSeen() {
 	}
 }
 
-func (s *Service) ImportData(file io.Reader, shipName string, cover bool, startDate, endDate int64) (*ImportDataResult, error) {
-	xlsx, err := excelize.OpenReader(file)
+// ImportData 同步执行一次导入，整个文件在一个事务内写入；ctx 取消时回滚事务，onProgress 可为 nil。
+// 支持 XLSX、CSV 和 Parquet，格式由文件头和扩展名识别
+func (s *Service) ImportData(ctx context.Context, file io.ReadSeeker, opts ImportOptions, onProgress func(ImportProgress)) (*ImportDataResult, error) {
+	opts.normalize()
+	ship, err := lookupShip(opts.ShipName)
 	if err != nil {
-		logger.Logger.Errorf("open excel file error: %v", err)
+		return nil, err
+	}
+	tracker := &importTracker{mode: opts.Mode, onProgress: onProgress}
+	tracker.report(ImportPhaseParsing)
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	tracker.fileHash = hex.EncodeToString(hasher.Sum(nil))
+	var imported *model.ImportBatch
+	if opts.DryRun {
+		// 预览不拒绝已导入的文件，在预览结果中给出已有的批次
+		if imported, err = findActiveBatch(s.db, tracker.fileHash); err != nil {
+			return nil, err
+		}
+	} else if err := checkDuplicateImport(s.db, tracker.fileHash); err != nil {
+		return nil, err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
 		return nil, err
 	}
 
-	rows, err := xlsx.GetRows(xlsx.GetSheetName(0))
+	rows, format, err := openRowSource(file, opts.FileName)
 	if err != nil {
+		logger.Logger.Errorf("打开导入文件 %s 失败: %v", opts.FileName, err)
 		return nil, err
 	}
+	defer rows.Close()
+	tracker.format = format
 
-	if len(rows) < 2 {
+	// 第一行为表头
+	if !rows.Next() {
+		if err = rows.Error(); err != nil {
+			return nil, err
+		}
 		return nil, errors.New("文件内容为空")
 	}
+	header, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("读取表头失败: %v", err)
+	}
+	rejectDir := s.RejectDir()
+	if opts.DryRun {
+		// 预览不能有副作用，被拒绝的行只在结果中返回明细
+		rejectDir = ""
+	}
+	tracker.rejects = newImportRejects(rejectDir, opts.FileName, header)
+	defer tracker.rejects.close()
 
-	tx := s.db.Begin()
-	defer func() {
-		if r := recover(); r != nil {
+	var (
+		tx    *gorm.DB
+		batch *model.ImportBatch
+	)
+	if opts.DryRun {
+		// 预览只查询数据库，不开启事务，也不写导入批次
+		tx = s.db.WithContext(ctx)
+		tracker.preview = &ImportPreview{Samples: []any{}}
+		if imported != nil {
+			tracker.preview.ImportedBatch = toImportBatch(imported)
+		}
+	} else {
+		tx = s.db.WithContext(ctx).Begin()
+		defer func() {
+			if r := recover(); r != nil {
+				tx.Rollback()
+			}
+		}()
+
+		batch = &model.ImportBatch{
+			JobID:      opts.JobID,
+			FileName:   opts.FileName,
+			FileHash:   tracker.fileHash,
+			ShipName:   opts.ShipName,
+			Uploader:   opts.Uploader,
+			Mode:       opts.Mode,
+			StartDate:  opts.StartDate,
+			EndDate:    opts.EndDate,
+			Status:     ImportBatchActive,
+			ActiveHash: &tracker.fileHash,
+		}
+		if err = tx.Create(batch).Error; err != nil {
 			tx.Rollback()
+			// 并发导入同一文件时，唯一索引 uk_import_batches_active_hash 使后提交的一方失败
+			if dupErr := checkDuplicateImport(s.db, tracker.fileHash); dupErr != nil {
+				return nil, dupErr
+			}
+			logger.Logger.Errorf("创建导入批次失败: %v", err)
+			return nil, err
 		}
-	}()
-
-	dataDates := []model.DataDate{
-		{ShipName: shipName, Date: startDate},
-		{ShipName: shipName, Date: endDate},
-	}
-	if err = tx.Clauses(clause.Insert{Modifier: "IGNORE"}).Create(&dataDates).Error; err != nil {
-		tx.Rollback()
-		return nil, err
+		tracker.batchID = batch.ID
 	}
 
-	var importedCount int
-
-	if strings.Contains(shipName, "华安龙") {
-		importedCount, err = executeImport[model.DredgerDataHl](tx, rows, shipName, cover)
-	} else {
-		importedCount, err = executeImport[model.DredgerDatum](tx, rows, shipName, cover)
+	switch ship.DataSchema {
+	case DataSchemaHL:
+		err = executeImport[model.DredgerDataHl](ctx, tx, header, rows, opts, tracker)
+	default:
+		err = executeImport[model.DredgerDatum](ctx, tx, header, rows, opts, tracker)
 	}
 
+	// 取消的任务不生成拒绝文件；导入失败时仍保存，便于排查
+	if ctx.Err() == nil {
+		tracker.rejectPath = tracker.rejects.save()
+	}
+	if opts.DryRun {
+		if err == nil {
+			tracker.report(ImportPhaseDone)
+		}
+		return tracker.result(), err
+	}
+	if err == nil && tracker.firstTime != 0 {
+		// 按实际写入的记录时间更新 data_date
+		if _, _, err = RefreshDataDates(tx, opts.ShipName, tracker.firstTime, tracker.lastTime); err != nil {
+			logger.Logger.Errorf("更新 data_date 失败: %v", err)
+		}
+	}
+	if err == nil {
+		err = tx.Model(batch).Updates(map[string]any{
+			"start_time":       tracker.firstTime,
+			"end_time":         tracker.lastTime,
+			"rows_parsed":      tracker.RowsParsed,
+			"rows_inserted":    tracker.buckets.Inserted,
+			"rows_overwritten": tracker.buckets.Overwritten,
+			"rows_unchanged":   tracker.buckets.Unchanged,
+			"rows_skipped":     tracker.RowsSkipped,
+		}).Error
+	}
 	if err != nil {
 		tx.Rollback()
-		return &ImportDataResult{importedCount}, err
+		return tracker.result(), err
 	}
 
+	tracker.report(ImportPhaseCommitting)
 	if err = tx.Commit().Error; err != nil {
-		return &ImportDataResult{importedCount}, fmt.Errorf("事务提交失败: %v", err)
+		return tracker.result(), fmt.Errorf("事务提交失败: %v", err)
 	}
+	tracker.report(ImportPhaseDone)
+
+	return tracker.result(), nil
+}
 
-	return &ImportDataResult{importedCount}, nil
+// classify 按导入模式将一个批次的行归入新增、覆盖、保持不变或冲突并计数，返回需要写入的行下标。
+// 与库中已有数据（existing）或本次导入中更早的行（seen）时间相同的行视为冲突，处理过的时间记入 seen；
+// fail 模式下 conflict 为第一个冲突行的下标，没有冲突时为 -1
+func (b *ImportBuckets) classify(timestamps []int64, existing, seen map[int64]struct{}, mode string) (writes []int, conflict int) {
+	conflict = -1
+	for i, ts := range timestamps {
+		_, dup := existing[ts]
+		if _, ok := seen[ts]; ok {
+			dup = true
+		}
+		seen[ts] = struct{}{}
+		if !dup {
+			b.Inserted++
+			writes = append(writes, i)
+			continue
+		}
+		switch mode {
+		case ImportModeOverwrite:
+			b.Overwritten++
+			writes = append(writes, i)
+		case ImportModeFail:
+			b.Conflicts++
+			if conflict < 0 {
+				conflict = i
+			}
+		default:
+			b.Unchanged++
+		}
+	}
+	return writes, conflict
 }
 
-func executeImport[T any](tx *gorm.DB, rows [][]string, shipName string, cover bool) (int, error) {
-	var imported int
-	var fieldNames []string
+// executeImport 按表头建立列映射后从 rows 迭代器逐行读取（表头已被调用方消费），每满 batchSize 行写入一次，
+// 内存占用只与批次大小有关，与文件总行数无关
+func executeImport[T any](ctx context.Context, tx *gorm.DB, header []string, rows rowSource, opts ImportOptions, tracker *importTracker) error {
+	shipName, mode := opts.ShipName, opts.Mode
 
-	// 使用反射获取模型 T 的字段名，跳过前两个字段（通常是 ID 和 ShipName）
 	modelType := reflect.TypeOf(*new(T))
-	for i := 2; i < modelType.NumField(); i++ {
-		fieldNames = append(fieldNames, modelType.Field(i).Name)
-	}
-
-	// 如果 cover 为 true，则删除将要被覆盖的旧数据
-	if cover {
-		var deleteTimestamps []int64
-		for _, row := range rows[1:] {
-			if len(row) > 1 {
-				// 从Excel的第二列解析时间戳
-				if t, err := time.ParseInLocation(time.DateTime, row[1], time.Local); err == nil {
-					deleteTimestamps = append(deleteTimestamps, t.UnixMilli())
-				}
+	mapping, err := buildColumnMapping(header, modelType, ShipLocation(shipName))
+	if err != nil {
+		return err
+	}
+	tracker.mapping = mapping.report
+	if len(mapping.report.UnknownHeaders) > 0 {
+		logger.Logger.Warnf("无法识别的列: %s", strings.Join(mapping.report.UnknownHeaders, ", "))
+	}
+
+	// 覆盖时只更新数据列：保留原有的 batch_id，回滚本批次时不会删除导入前已存在的行
+	var overwriteColumns []string
+	for _, c := range importableColumns(modelType) {
+		if c.column != "record_time" {
+			overwriteColumns = append(overwriteColumns, c.column)
+		}
+	}
+
+	// 初始化用于批量插入的切片
+	batch := make([]*T, 0, batchSize)
+	batchTimestamps := make([]int64, 0, batchSize)
+	batchRowNums := make([]rowPosition, 0, batchSize)
+	// 本次导入中已处理过的时间戳，文件内部重复的时间与已有数据冲突同样处理
+	seen := make(map[int64]struct{})
+	preview := tracker.preview
+
+	// 按导入模式写入一个批次：先查出库中已存在的时间戳，将每行归入新增/覆盖/保持不变，
+	// 再用 ON DUPLICATE KEY 语义写入；预览模式只做归类统计
+	flush := func() error {
+		var lookup []int64
+		for _, ts := range batchTimestamps {
+			if _, ok := seen[ts]; !ok {
+				lookup = append(lookup, ts)
 			}
 		}
-		if len(deleteTimestamps) > 0 {
-			// 使用模型T来指定要删除的表
-			err := tx.Where("record_time IN (?) AND ship_name = ?", deleteTimestamps, shipName).Delete(new(T)).Error
+		existing := make(map[int64]struct{})
+		if len(lookup) > 0 {
+			var found []int64
+			err := tx.Model(new(T)).Where("ship_name = ? AND record_time IN (?)", shipName, lookup).Pluck("record_time", &found).Error
 			if err != nil {
-				logger.Logger.Errorf("覆盖数据时，删除旧数据失败: %v", err)
-				return 0, err
+				logger.Logger.Errorf("查询已有数据失败: %v", err)
+				return err
+			}
+			for _, ts := range found {
+				existing[ts] = struct{}{}
 			}
 		}
-	}
 
-	// 初始化用于批量插入的切片
-	batch := make([]*T, 0, batchSize)
+		indexes, conflict := tracker.buckets.classify(batchTimestamps, existing, seen, mode)
+		if conflict >= 0 && preview == nil {
+			ts := batchTimestamps[conflict]
+			return fmt.Errorf("%s的时间 %s 与已有数据冲突", batchRowNums[conflict], time.UnixMilli(ts).In(mapping.loc).Format(time.DateTime))
+		}
+		writes := make([]*T, 0, len(indexes))
+		for _, i := range indexes {
+			writes = append(writes, batch[i])
+		}
 
-	for rowNum, row := range rows[1:] {
-		if len(row) < len(fieldNames) {
-			logger.Logger.Warnf("第 %d 行的列数不足（%d/%d），已跳过", rowNum+2, len(row), len(fieldNames))
-			continue
+		if preview != nil {
+			tracker.report(ImportPhaseParsing)
+		} else if len(writes) > 0 {
+			query := tx
+			switch mode {
+			case ImportModeOverwrite:
+				query = tx.Clauses(clause.OnConflict{DoUpdates: clause.AssignmentColumns(overwriteColumns)})
+			case ImportModeSkip:
+				query = tx.Clauses(clause.OnConflict{DoNothing: true})
+			}
+			if err := query.Create(&writes).Error; err != nil {
+				return err
+			}
+			tracker.RowsInserted += len(writes)
+			tracker.report(ImportPhaseInserting)
+		}
+		batch = make([]*T, 0, batchSize) // 重置切片
+		batchTimestamps = batchTimestamps[:0]
+		batchRowNums = batchRowNums[:0]
+		return nil
+	}
+	previewSamples := conf.Conf.GetInt("import.previewSamples")
+
+	for rowNum := 2; rows.Next(); rowNum++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pos := rowPosition{Row: rowNum}
+		if sheets, ok := rows.(sheetSource); ok {
+			pos = sheets.Position()
 		}
+		row, err := rows.Columns()
+		if err != nil {
+			return fmt.Errorf("读取%s失败: %v", pos, err)
+		}
+		tracker.RowsParsed++
 
 		// 创建模型T的新实例
 		dataInstance := reflect.New(modelType).Elem()
 		dataInstance.FieldByName("ShipName").SetString(shipName)
+		dataInstance.FieldByName("BatchID").SetInt(tracker.batchID)
 
-		validRow := true
-		for i, fieldName := range fieldNames {
-			cellVal := row[i+1] // 从Excel第二列开始取值
-			field := dataInstance.FieldByName(fieldName)
-
-			if !field.CanSet() {
-				logger.Logger.Warnf("第 %d 行的字段 %s 无法设置，已跳过", rowNum+2, fieldName)
-				validRow = false
-				break
-			}
-
-			// 时间字段（RecordTime）特殊处理
-			if i == 0 {
-				if timestamp, err := time.ParseInLocation(time.DateTime, cellVal, time.Local); err != nil {
-					logger.Logger.Warnf("第 %d 行的时间字段 %s 转换失败: %v", rowNum+2, fieldName, err)
-					validRow = false
-				} else {
-					field.SetInt(timestamp.UnixMilli())
-				}
-				continue
-			}
-
-			// 根据字段类型进行转换和赋值
-			switch field.Kind() {
-			case reflect.Float64, reflect.Float32:
-				if num, err := strconv.ParseFloat(cellVal, 64); err == nil {
-					field.SetFloat(num)
-				} else if cellVal != "" {
-					logger.Logger.Warnf("第 %d 行的浮点数字段 %s 转换失败: %v", rowNum+2, fieldName, err)
-					validRow = false
-				}
-			case reflect.Int32, reflect.Int64:
-				if num, err := strconv.ParseInt(cellVal, 10, 64); err == nil {
-					field.SetInt(num)
-				} else if cellVal != "" {
-					logger.Logger.Warnf("第 %d 行的整数字段 %s 转换失败: %v", rowNum+2, fieldName, err)
-					validRow = false
-				}
-			case reflect.String:
-				field.SetString(cellVal)
-			default:
-				logger.Logger.Warnf("第 %d 行的字段 %s 是不支持的类型 %s，已跳过", rowNum+2, fieldName, field.Kind())
-				validRow = false
-			}
-
-			if !validRow {
-				break
-			}
+		recordTime, rejection := mapping.convertRow(row, dataInstance)
+		if rejection != nil {
+			tracker.reject(pos, row, rejection)
+			continue
 		}
-
-		if validRow {
-			// 将转换后的数据指针添加到批处理切片中
-			batch = append(batch, dataInstance.Addr().Interface().(*T))
-		} else {
-			logger.Logger.Warnf("第 %d 行数据格式有误，已跳过", rowNum+2)
+		// 将转换后的数据指针添加到批处理切片中
+		record := dataInstance.Addr().Interface().(*T)
+		batch = append(batch, record)
+		batchTimestamps = append(batchTimestamps, recordTime)
+		batchRowNums = append(batchRowNums, pos)
+		tracker.observe(recordTime)
+		if preview != nil && len(preview.Samples) < previewSamples {
+			preview.Samples = append(preview.Samples, record)
 		}
 
 		// 当批处理切片达到指定大小时，执行插入并清空切片
 		if len(batch) >= batchSize {
-			if err := tx.Create(&batch).Error; err != nil {
-				return imported, fmt.Errorf("插入第 %d 批次时出错: %v", (imported/batchSize)+1, err)
+			if err := flush(); err != nil {
+				return fmt.Errorf("插入%s之前的批次时出错: %v", pos, err)
 			}
-			imported += len(batch)
-			batch = make([]*T, 0, batchSize) // 重置切片
 		}
 	}
+	if err := rows.Error(); err != nil {
+		return fmt.Errorf("读取文件失败: %v", err)
+	}
+
+	if tracker.RowsParsed == 0 {
+		return errors.New("文件内容为空")
+	}
+	if tracker.RowsSkipped > 0 {
+		logger.Logger.Warnf("共 %d 行数据格式有误，已跳过: %v", tracker.RowsSkipped, tracker.rejects.counts)
+	}
 
 	// 插入最后一批剩余的数据
 	if len(batch) > 0 {
-		if err := tx.Create(&batch).Error; err != nil {
-			return imported, fmt.Errorf("插入最后批次时出错: %v", err)
+		if err := flush(); err != nil {
+			return fmt.Errorf("插入最后批次时出错: %v", err)
 		}
-		imported += len(batch)
 	}
 
-	return imported, nil
+	return nil
 }
 
-func (s *Service) GetShiftStats(shipName string, startTime, endTime int64) ([]*ShiftStat, error) {
-	var stats []*ShiftStat
-	var err error
+// GetShiftStats 统计各班组的施工时长、产量和能耗，dimension 为 crew 时按值班班组统计，否则按班次
+func (s *Service) GetShiftStats(shipName string, startTime, endTime int64, dimension string) ([]*ShiftStat, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	// 班次按船舶登记的班次安排在船舶所在时区划分
+	loc := ShipLocation(shipName)
 
 	// 1. 在函数开始时，一次性加载所有土质区域数据
-	var allRegions []model.SoilRegion
-	if err = s.db.Find(&allRegions).Error; err != nil {
-		logger.Logger.Errorf("加载土质区域数据失败: %v", err)
+	soil, err := s.loadSoilClassifier(ship)
+	if err != nil {
 		return nil, err
 	}
 
-	if strings.Contains(shipName, "华安龙") {
-		var records []*model.DredgerDataHl
-		// 确保查询了计算土质所需的 cutter_x, cutter_y, bridge_depth
-		columns := []string{
-			"ship_name", "record_time", "hourly_output_rate",
-			"underwater_pump_power", "mud_pump_1_power", "mud_pump_2_power",
-			"cutter_x", "cutter_y", "bridge_depth",
-		}
-		err = s.db.Select(columns).
-			Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[华安龙]查询班组统计数据失败: %v", err)
-			return nil, err
-		}
-
-		groups := make(map[int][]*model.DredgerDataHl)
-		for _, record := range records {
-			hour := time.UnixMilli(record.RecordTime).Hour()
-			switch {
-			case hour >= 0 && hour < 6:
-				groups[1] = append(groups[1], record)
-			case hour >= 6 && hour < 12:
-				groups[2] = append(groups[2], record)
-			case hour >= 12 && hour < 18:
-				groups[3] = append(groups[3], record)
-			default:
-				groups[4] = append(groups[4], record)
-			}
-		}
-
-		for shift := 1; shift <= 4; shift++ {
-			shiftRecords, exists := groups[shift]
-			if !exists || len(shiftRecords) == 0 {
-				continue
-			}
-
-			var minTime, maxTime time.Time
-			maxTime, minTime = durationMinutesHl(minTime, maxTime, shiftRecords)
-			duration := calculateAccumulatedDurationHl(shiftRecords)
-			if duration <= 0 {
-				continue
-			}
-
-			var totalOutputRate float64
-			for _, r := range shiftRecords {
-				totalOutputRate += r.HourlyOutputRate
-			}
-			avgOutputRate := totalOutputRate / float64(len(shiftRecords))
-			totalProduction := avgOutputRate * (duration / 60)
-
-			var totalPower float64
-			for _, r := range shiftRecords {
-				totalPower += r.UnderwaterPumpPower + r.MudPump1Power + r.MudPump2Power
-			}
-			avgPower := totalPower / float64(len(shiftRecords))
-			totalEnergyConsumption := avgPower * (duration / 60)
-			unitEnergyConsumption := 0.0
-			if totalProduction > 0 {
-				unitEnergyConsumption = totalEnergyConsumption / totalProduction
-			}
-
-			// 2. 计算当前班组遇到的所有土质
-			soilTypesMap := make(map[string]struct{})
-			for _, r := range shiftRecords {
-				// 使用 "bridge_depth" 作为 Z 坐标
-				soilType := findSoilType(r.CutterX, r.CutterY, r.BridgeDepth, allRegions)
-				soilTypesMap[soilType] = struct{}{}
-			}
-			var soilTypes []string
-			for soil := range soilTypesMap {
-				soilTypes = append(soilTypes, soil)
-			}
-			sort.Strings(soilTypes) // 保证顺序一致
-
-			stats = append(stats, &ShiftStat{
-				ShiftName:       shiftName(shift),
-				BeginTime:       minTime,
-				EndTime:         maxTime,
-				WorkDuration:    duration,
-				TotalProduction: round(totalProduction),
-				TotalEnergy:     round(unitEnergyConsumption),
-				SoilTypes:       soilTypes, // 3. 将土质数据添加到响应中
-			})
-		}
-	} else {
-		var records []*model.DredgerDatum
-		// 确保查询了计算土质所需的 cutter_x, cutter_y, cutter_depth
-		columns := []string{
-			"ship_name", "record_time", "output_rate", "current_shift_output_rate", "underwater_pump_suction_vacuum",
-			"intermediate_pressure", "booster_pump_discharge_pressure", "flow_rate",
-			"cutter_x", "cutter_y", "cutter_depth",
-		}
-		err = s.db.Select(columns).
-			Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[敏龙]查询班组统计数据失败: %v", err)
-			return nil, err
-		}
-
-		groups := make(map[int][]*model.DredgerDatum)
-		for _, record := range records {
-			hour := time.UnixMilli(record.RecordTime).Hour()
-			switch {
-			case hour >= 0 && hour < 6:
-				groups[1] = append(groups[1], record)
-			case hour >= 6 && hour < 12:
-				groups[2] = append(groups[2], record)
-			case hour >= 12 && hour < 18:
-				groups[3] = append(groups[3], record)
-			default:
-				groups[4] = append(groups[4], record)
-			}
-		}
-
-		for shift := 1; shift <= 4; shift++ {
-			shiftRecords, exists := groups[shift]
-			if !exists || len(shiftRecords) == 0 {
-				continue
-			}
-
-			var minTime, maxTime time.Time
-			maxTime, minTime = durationMinutes(minTime, maxTime, shiftRecords)
-			duration := calculateAccumulatedDuration(shiftRecords)
-			if duration <= 0 {
-				continue
-			}
+	records, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询班组统计数据失败: %v", shipName, err)
+		return nil, err
+	}
 
-			var totalOutputRate float64
-			for _, r := range shiftRecords {
-				totalOutputRate += r.CurrentShiftOutputRate
-			}
-			avgOutputRate := totalOutputRate / float64(len(shiftRecords))
-			totalProduction := avgOutputRate * (duration / 60)
-
-			var totalPower float64
-			for _, r := range shiftRecords {
-				P1 := r.UnderwaterPumpSuctionVacuum
-				P2 := r.IntermediatePressure
-				P3 := r.BoosterPumpDischargePressure
-				Q := r.FlowRate
-				pw1 := 0.8 * Q * (P2 - P1)
-				pw2 := 0.8 * Q * (P3 - P2)
-				pw := pw1 + pw2
-				totalPower += pw
-			}
-			avgPower := totalPower / float64(len(shiftRecords))
-			totalEnergyConsumption := avgPower * (duration / 60)
-			unitEnergyConsumption := 0.0
-			if totalProduction > 0 {
-				unitEnergyConsumption = totalEnergyConsumption / totalProduction
-			}
+	keys, groups, err := s.groupRecords(ship, loc, records, startTime, endTime, dimension)
+	if err != nil {
+		logger.Logger.Errorf("[%s]%v", shipName, err)
+		return nil, err
+	}
 
-			// 2. 计算当前班组遇到的所有土质
-			soilTypesMap := make(map[string]struct{})
-			for _, r := range shiftRecords {
-				// 使用 "cutter_depth" 作为 Z 坐标
-				soilType := findSoilType(r.CutterX, r.CutterY, r.CutterDepth, allRegions)
-				soilTypesMap[soilType] = struct{}{}
-			}
-			var soilTypes []string
-			for soil := range soilTypesMap {
-				soilTypes = append(soilTypes, soil)
-			}
-			sort.Strings(soilTypes)
-
-			stats = append(stats, &ShiftStat{
-				ShiftName:       shiftName(shift),
-				BeginTime:       minTime,
-				EndTime:         maxTime,
-				WorkDuration:    duration,
-				TotalProduction: round(totalProduction),
-				TotalEnergy:     round(unitEnergyConsumption),
-				SoilTypes:       soilTypes, // 3. 将土质数据添加到响应中
-			})
+	var stats []*ShiftStat
+	for _, key := range keys {
+		if stat, _ := newShiftStat(key, dimension, groups[key], soil, loc, ship); stat != nil {
+			stats = append(stats, stat)
 		}
 	}
 
 	sort.Slice(stats, func(i, j int) bool {
 		if stats[i].BeginTime.Equal(stats[j].BeginTime) {
-			return stats[i].ShiftName < stats[j].ShiftName
+			return stats[i].ShiftName+stats[i].Crew < stats[j].ShiftName+stats[j].Crew
 		}
 		return stats[i].BeginTime.Before(stats[j].BeginTime)
 	})
@@ -483,48 +487,39 @@ func (s *Service) GetShiftStats(shipName string, startTime, endTime int64) ([]*S
 	return stats, nil
 }
 
-func (s *Service) GetOptimalShift(shipName string, startTime, endTime int64) (*OptimalShiftResponse, error) {
+// GetOptimalShift 找出每种土质下产量最大和能耗最小的班组，dimension 为 crew 时比较值班班组，否则比较班次；
+// groupBy 为 soil 时按土质分组，shift 时不区分土质，both 时两者都返回，为空时按船舶登记的土质模型
+func (s *Service) GetOptimalShift(shipName string, startTime, endTime int64, dimension, groupBy string) (*OptimalShiftResponse, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	// 班次按船舶登记的班次安排在船舶所在时区划分
+	loc := ShipLocation(shipName)
+
 	// 1. 初始化最终的响应结构
 	response := &OptimalShiftResponse{
 		OptimalShiftsBySoil: make(map[string]*OptimalShift),
 	}
-	var err error
 
-	// 2. 根据船名决定是否加载土质数据
-	var allRegions []model.SoilRegion
-	isMinLong := strings.Contains(shipName, "敏龙")
-	if isMinLong { // 只有敏龙需要土质数据
-		if err = s.db.Find(&allRegions).Error; err != nil {
-			logger.Logger.Errorf("加载土质区域数据失败: %v", err)
-			return nil, err
-		}
+	allRecords, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询最优班组数据失败: %v", shipName, err)
+		return nil, err
+	}
+	if len(allRecords) == 0 {
+		return response, nil
 	}
 
-	// ---------- 华安龙 ----------
-	if strings.Contains(shipName, "华安龙") {
-		// 查询所需的全部字段
-		columns := []string{
-			"ship_name", "record_time", "hourly_output_rate", "transverse_speed",
-			"trolley_travel", "bridge_depth", "underwater_pump_speed", "concentration",
-			"flow_rate", "underwater_pump_discharge_pressure", "cutter_x", "cutter_y",
-			"water_density", "density", "field_slurry_density", "flow_velocity",
-			"ear_draft", "left_ear_draft", "right_ear_draft", "underwater_pump_power",
-			"mud_pump_1_power", "mud_pump_2_power", "mud_pump_1_discharge_pressure", "mud_pump_2_discharge_pressure",
-		}
-		var allRecords []*model.DredgerDataHl
-		err = s.db.Select(columns).Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&allRecords).Error
-		if err != nil {
-			logger.Logger.Errorf("[华安龙]查询最优班组数据失败: %v", err)
-			return nil, err
-		}
-		if len(allRecords) == 0 {
-			return response, nil
-		}
+	// 2. 按分组方式对所有记录进行分组，不区分土质时所有数据归到 "default" 组
+	recordsBySoil, err := s.recordsBySoil(ship, allRecords, groupBy)
+	if err != nil {
+		return nil, err
+	}
 
-		// 3. 华安龙：不按土质分组，直接计算
-		optimalShiftForShip := &OptimalShift{
+	// 3. 对每种土质(或 "default")的数据，分别找出产量最大和能耗最小的班组
+	for soilType, records := range recordsBySoil {
+		optimalShiftForSoil := &OptimalShift{
 			MinEnergyShift: &ShiftWorkParams{
 				Parameters: ParameterStats{
 					BoosterPumpDischargePressure: Parameter{Max: -1},
@@ -532,272 +527,66 @@ func (s *Service) GetOptimalShift(shipName string, startTime, endTime int64) (*O
 			},
 		}
 
-		// 按班组对所有记录进行分组
-		groups := make(map[int][]*model.DredgerDataHl)
-		for _, record := range allRecords {
-			hour := time.UnixMilli(record.RecordTime).Hour()
-			switch {
-			case hour >= 0 && hour < 6:
-				groups[1] = append(groups[1], record)
-			case hour >= 6 && hour < 12:
-				groups[2] = append(groups[2], record)
-			case hour >= 12 && hour < 18:
-				groups[3] = append(groups[3], record)
-			default:
-				groups[4] = append(groups[4], record)
-			}
+		keys, groups, err := s.groupRecords(ship, loc, records, startTime, endTime, dimension)
+		if err != nil {
+			logger.Logger.Errorf("[%s]%v", shipName, err)
+			return nil, err
 		}
-
-		cfg := GetCfg(shipName)
-
-		for shift := 1; shift <= 4; shift++ {
-			shiftRecords, exists := groups[shift]
-			if !exists || len(shiftRecords) == 0 {
+		for _, key := range keys {
+			shiftRecords := groups[key]
+			work := measureWorkload(shiftRecords, loc, ship.Duration)
+			if work.Duration <= 0 {
 				continue
 			}
 
-			var minTime, maxTime time.Time
-			maxTime, minTime = durationMinutesHl(minTime, maxTime, shiftRecords)
-			duration := calculateAccumulatedDurationHl(shiftRecords)
-			if duration <= 0 {
-				continue
-			}
-
-			var totalOutputRate float64
-			for _, r := range shiftRecords {
-				totalOutputRate += r.HourlyOutputRate
-			}
-			avgOutputRate := totalOutputRate / float64(len(shiftRecords))
-			totalProduction := avgOutputRate * (duration / 60)
-
-			avgVacHl, okVacHl := averageVacuumHL(shiftRecords, cfg)
-
-			var totalPower float64
-			for _, r := range shiftRecords {
-				totalPower += r.UnderwaterPumpPower + r.MudPump1Power + r.MudPump2Power
-			}
-			avgPower := totalPower / float64(len(shiftRecords))
-			totalEnergy := avgPower * (duration / 60)
-
 			// 更新最大产量班组
-			if totalProduction > optimalShiftForShip.TotalProduction {
-				optimalShiftForShip.TotalProduction = round(totalProduction)
-				params, optimalTime := calParamsHl(shiftRecords)
-				if okVacHl {
-					params.VacuumDegree.Average = round(avgVacHl)
-				}
-				if math.IsNaN(params.VacuumDegree.MaxProductionParam) || math.IsInf(params.VacuumDegree.MaxProductionParam, 0) {
-					params.VacuumDegree.MaxProductionParam = 0
-				}
-				optimalShiftForShip.MaxProductionShift = &ShiftWorkParams{
-					ShiftName:   shiftName(shift),
-					Parameters:  params,
-					OptimalTime: optimalTime,
-				}
+			if work.Production > optimalShiftForSoil.TotalProduction {
+				optimalShiftForSoil.TotalProduction = round(work.Production)
+				params, optimalTime := calParams(shiftRecords, ship.Hydraulics)
+				optimalShiftForSoil.MaxProductionShift = newShiftWorkParams(key, dimension, params, optimalTime)
 			}
 
 			// 更新最小能耗班组
-			if optimalShiftForShip.MinEnergyShift.Parameters.BoosterPumpDischargePressure.Max == -1 || totalEnergy < optimalShiftForShip.TotalEnergy {
-				optimalShiftForShip.TotalEnergy = round(totalEnergy)
-				params, optimalTime := calParamsHl(shiftRecords)
-				if okVacHl {
-					params.VacuumDegree.Average = round(avgVacHl)
-				}
-				if math.IsNaN(params.VacuumDegree.MaxProductionParam) || math.IsInf(params.VacuumDegree.MaxProductionParam, 0) {
-					params.VacuumDegree.MaxProductionParam = 0
-				}
-				optimalShiftForShip.MinEnergyShift = &ShiftWorkParams{
-					ShiftName:   shiftName(shift),
-					Parameters:  params,
-					OptimalTime: optimalTime,
-				}
-			}
-		}
-		// 华安龙的结果使用 "default" 作为 key
-		response.OptimalShiftsBySoil["default"] = optimalShiftForShip
-
-		// ---------- 敏龙 ----------
-	} else {
-		columns := []string{
-			"ship_name", "record_time", "current_shift_output_rate", "transverse_speed",
-			"trolley_travel", "cutter_depth", "underwater_pump_speed", "concentration",
-			"flow_rate", "booster_pump_discharge_pressure", "underwater_pump_suction_vacuum",
-			"intermediate_pressure", "water_density", "density", "field_slurry_density",
-			"flow_velocity", "mud_pipe_diameter", "ear_draft", "left_ear_draft",
-			"right_ear_draft", "ear_to_bottom_distance", "cutter_x", "cutter_y",
-		}
-		var allRecords []*model.DredgerDatum
-		err = s.db.Select(columns).Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&allRecords).Error
-		if err != nil {
-			logger.Logger.Errorf("[敏龙]查询最优班组数据失败: %v", err)
-			return nil, err
-		}
-		if len(allRecords) == 0 {
-			return response, nil
-		}
-
-		// 根据 isMinLong 决定如何分组 ***
-		recordsBySoil := make(map[string][]*model.DredgerDatum)
-		if isMinLong {
-			// 敏龙：按土质对所有记录进行分组 (allRegions 已在函数开头加载)
-			for _, record := range allRecords {
-				soilType := findSoilType(record.CutterX, record.CutterY, record.CutterDepth, allRegions)
-				recordsBySoil[soilType] = append(recordsBySoil[soilType], record)
-			}
-		} else {
-			// 其他船：不分土质，所有数据归到 "default" 组
-			recordsBySoil["default"] = allRecords
-		}
-
-		// 4. 对每种土质(或 "default")的数据，分别计算最优参数
-		for soilType, records := range recordsBySoil {
-			optimalShiftForSoil := &OptimalShift{
-				MinEnergyShift: &ShiftWorkParams{
-					Parameters: ParameterStats{
-						BoosterPumpDischargePressure: Parameter{Max: -1},
-					},
-				},
-			}
-
-			groups := make(map[int][]*model.DredgerDatum)
-			for _, record := range records {
-				hour := time.UnixMilli(record.RecordTime).Hour()
-				switch {
-				case hour >= 0 && hour < 6:
-					groups[1] = append(groups[1], record)
-				case hour >= 6 && hour < 12:
-					groups[2] = append(groups[2], record)
-				case hour >= 12 && hour < 18:
-					groups[3] = append(groups[3], record)
-				default:
-					groups[4] = append(groups[4], record)
-				}
-			}
-
-			cfg := GetCfg(shipName)
-
-			for shift := 1; shift <= 4; shift++ {
-				shiftRecords, exists := groups[shift]
-				if !exists || len(shiftRecords) == 0 {
-					continue
-				}
-
-				var minTime, maxTime time.Time
-				maxTime, minTime = durationMinutes(minTime, maxTime, shiftRecords)
-				duration := calculateAccumulatedDuration(shiftRecords)
-				if duration <= 0 {
-					continue
-				}
-
-				var totalOutputRate float64
-				for _, r := range shiftRecords {
-					totalOutputRate += r.CurrentShiftOutputRate
-				}
-				avgOutputRate := totalOutputRate / float64(len(shiftRecords))
-				totalProduction := avgOutputRate * (duration / 60)
-
-				avgVac, okVac := averageVacuumDatum(shiftRecords, cfg)
-
-				var totalPower float64
-				for _, r := range shiftRecords {
-					P1 := r.UnderwaterPumpSuctionVacuum
-					P2 := r.IntermediatePressure
-					P3 := r.BoosterPumpDischargePressure
-					Q := r.FlowRate
-					pw1 := 0.8 * Q * (P2 - P1)
-					pw2 := 0.8 * Q * (P3 - P2)
-					totalPower += pw1 + pw2
-				}
-				avgPower := totalPower / float64(len(shiftRecords))
-				totalEnergy := avgPower * (duration / 60)
-
-				if totalProduction > optimalShiftForSoil.TotalProduction {
-					optimalShiftForSoil.TotalProduction = round(totalProduction)
-					params, optimalTime := calParams(shiftRecords)
-					if okVac {
-						params.VacuumDegree.Average = round(avgVac)
-					}
-					if math.IsNaN(params.VacuumDegree.MaxProductionParam) || math.IsInf(params.VacuumDegree.MaxProductionParam, 0) {
-						params.VacuumDegree.MaxProductionParam = 0
-					}
-					optimalShiftForSoil.MaxProductionShift = &ShiftWorkParams{
-						ShiftName:   shiftName(shift),
-						Parameters:  params,
-						OptimalTime: optimalTime,
-					}
-				}
-
-				if optimalShiftForSoil.MinEnergyShift.Parameters.BoosterPumpDischargePressure.Max == -1 || totalEnergy < optimalShiftForSoil.TotalEnergy {
-					optimalShiftForSoil.TotalEnergy = round(totalEnergy)
-					params, optimalTime := calParams(shiftRecords)
-					if okVac {
-						params.VacuumDegree.Average = round(avgVac)
-					}
-					if math.IsNaN(params.VacuumDegree.MaxProductionParam) || math.IsInf(params.VacuumDegree.MaxProductionParam, 0) {
-						params.VacuumDegree.MaxProductionParam = 0
-					}
-					optimalShiftForSoil.MinEnergyShift = &ShiftWorkParams{
-						ShiftName:   shiftName(shift),
-						Parameters:  params,
-						OptimalTime: optimalTime,
-					}
-				}
+			if optimalShiftForSoil.MinEnergyShift.Parameters.BoosterPumpDischargePressure.Max == -1 || work.Energy < optimalShiftForSoil.TotalEnergy {
+				optimalShiftForSoil.TotalEnergy = round(work.Energy)
+				params, optimalTime := calParams(shiftRecords, ship.Hydraulics)
+				optimalShiftForSoil.MinEnergyShift = newShiftWorkParams(key, dimension, params, optimalTime)
 			}
-			response.OptimalShiftsBySoil[soilType] = optimalShiftForSoil
 		}
+		response.OptimalShiftsBySoil[soilType] = optimalShiftForSoil
 	}
 
 	return response, nil
 }
 
+// GetShipList 返回所有登记的船名
 func (s *Service) GetShipList() ([]string, error) {
-	var ships1, ships2 []string
-	var allShips []string
-	shipMap := make(map[string]bool)
-
-	err := s.db.Model(&model.DredgerDatum{}).Distinct().Pluck("ship_name", &ships1).Error
-	if err != nil {
-		logger.Logger.Errorf("查询船名列表(dredger_data)出错: %v", err)
-		return nil, err
-	}
-	for _, ship := range ships1 {
-		if !shipMap[ship] {
-			shipMap[ship] = true
-			allShips = append(allShips, ship)
-		}
-	}
-
-	err = s.db.Model(&model.DredgerDataHl{}).Distinct().Pluck("ship_name", &ships2).Error
+	ships, err := registry.all()
 	if err != nil {
-		logger.Logger.Errorf("查询船名列表(dredger_data_hl)出错: %v", err)
+		logger.Logger.Errorf("查询船名列表出错: %v", err)
 		return nil, err
 	}
-	for _, ship := range ships2 {
-		if !shipMap[ship] {
-			shipMap[ship] = true
-			allShips = append(allShips, ship)
-		}
+	allShips := make([]string, 0, len(ships))
+	for name := range ships {
+		allShips = append(allShips, name)
 	}
 
 	sort.Sort(sort.Reverse(sort.StringSlice(allShips)))
 	return allShips, nil
 }
 
-func (s *Service) GetColumns(shipName string) []*ColumnInfo {
-	var refType reflect.Type
-
-	if strings.Contains(shipName, "华安龙") {
-		refType = reflect.TypeOf(model.DredgerDataHl{})
-	} else {
-		refType = reflect.TypeOf(model.DredgerDatum{})
+func (s *Service) GetColumns(shipName string) ([]*ColumnInfo, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
 	}
+	refType := ship.dataModelType()
 
 	excludes := map[string]bool{
 		"ID":         true,
 		"ShipName":   true,
 		"RecordTime": true,
+		"BatchID":    true,
 	}
 
 	var columns []*ColumnInfo
@@ -805,16 +594,7 @@ func (s *Service) GetColumns(shipName string) []*ColumnInfo {
 		field := refType.Field(i)
 
 		if !excludes[field.Name] {
-			tag := field.Tag.Get("gorm")
-			parts := strings.Split(tag, ";")
-			column := strings.TrimPrefix(parts[0], "column:")
-			var columnCN string
-			for _, part := range parts {
-				if strings.HasPrefix(part, "comment:") {
-					columnCN = strings.TrimPrefix(part, "comment:")
-					break
-				}
-			}
+			column, columnCN := parseGormTag(field.Tag.Get("gorm"))
 			columns = append(columns, &ColumnInfo{
 				ColumnName:        column,
 				ColumnChineseName: columnCN,
@@ -823,166 +603,69 @@ func (s *Service) GetColumns(shipName string) []*ColumnInfo {
 		}
 	}
 
-	return columns
+	return columns, nil
 }
 
-func (s *Service) GetShiftPie(shipName string, startTime, endTime int64) ([]*ShiftPie, error) {
-	var pies []*ShiftPie
-	var err error
-
-	if strings.Contains(shipName, "华安龙") {
-		var records []*model.DredgerDataHl
-		err = s.db.Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[华安龙]查询班组饼图数据失败: %v", err)
-			return nil, err
-		}
-
-		groups := make(map[int][]*model.DredgerDataHl)
-		for _, record := range records {
-			hour := time.UnixMilli(record.RecordTime).Hour()
-			switch {
-			case hour >= 0 && hour < 6:
-				groups[1] = append(groups[1], record)
-			case hour >= 6 && hour < 12:
-				groups[2] = append(groups[2], record)
-			case hour >= 12 && hour < 18:
-				groups[3] = append(groups[3], record)
-			default:
-				groups[4] = append(groups[4], record)
-			}
-		}
-
-		for shift := 1; shift <= 4; shift++ {
-			shiftRecords, exists := groups[shift]
-			if !exists || len(shiftRecords) == 0 {
-				continue
-			}
+// GetShiftPie 返回各班组的产量、能耗和时长占比，dimension 为 crew 时按值班班组，否则按班次
+func (s *Service) GetShiftPie(shipName string, startTime, endTime int64, dimension string) ([]*ShiftPie, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	// 班次按船舶登记的班次安排在船舶所在时区划分
+	loc := ShipLocation(shipName)
 
-			var minTime, maxTime time.Time
-			maxTime, minTime = durationMinutesHl(minTime, maxTime, shiftRecords)
-			duration := calculateAccumulatedDurationHl(shiftRecords)
-			if duration <= 0 {
-				continue
-			}
+	records, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询班组饼图数据失败: %v", shipName, err)
+		return nil, err
+	}
 
-			var totalOutputRate float64
-			for _, r := range shiftRecords {
-				totalOutputRate += r.HourlyOutputRate
-			}
-			avgOutputRate := totalOutputRate / float64(len(shiftRecords))
-			totalProduction := avgOutputRate * (duration / 60)
+	keys, groups, err := s.groupRecords(ship, loc, records, startTime, endTime, dimension)
+	if err != nil {
+		logger.Logger.Errorf("[%s]%v", shipName, err)
+		return nil, err
+	}
 
-			var totalPower float64
-			for _, r := range shiftRecords {
-				totalPower += r.UnderwaterPumpPower + r.MudPump1Power + r.MudPump2Power
-			}
-			avgPower := totalPower / float64(len(shiftRecords))
-			totalEnergy := avgPower * (duration / 60)
-
-			pies = append(pies, &ShiftPie{
-				ShiftName: shiftName(shift),
-				WorkData: &PieData{
-					TotalProduction: round(totalProduction),
-					TotalEnergy:     round(totalEnergy),
-					WorkDuration:    duration,
-				},
-			})
-		}
-	} else {
-		var records []*model.DredgerDatum
-		err = s.db.Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[敏龙]查询班组饼图数据失败: %v", err)
-			return nil, err
+	var pies []*ShiftPie
+	for _, key := range keys {
+		work := measureWorkload(groups[key], loc, ship.Duration)
+		if work.Duration <= 0 {
+			continue
 		}
-
-		groups := make(map[int][]*model.DredgerDatum)
-		for _, record := range records {
-			hour := time.UnixMilli(record.RecordTime).Hour()
-			switch {
-			case hour >= 0 && hour < 6:
-				groups[1] = append(groups[1], record)
-			case hour >= 6 && hour < 12:
-				groups[2] = append(groups[2], record)
-			case hour >= 12 && hour < 18:
-				groups[3] = append(groups[3], record)
-			default:
-				groups[4] = append(groups[4], record)
-			}
+		pie := &ShiftPie{
+			WorkData: &PieData{
+				TotalProduction: round(work.Production),
+				TotalEnergy:     round(work.Energy),
+				EnergyUnit:      ship.Energy.energyUnit(),
+				TotalFuel:       round(work.Fuel),
+				WorkDuration:    work.Duration,
+			},
 		}
-
-		for shift := 1; shift <= 4; shift++ {
-			shiftRecords, exists := groups[shift]
-			if !exists || len(shiftRecords) == 0 {
-				continue
-			}
-
-			var minTime, maxTime time.Time
-			maxTime, minTime = durationMinutes(minTime, maxTime, shiftRecords)
-			duration := calculateAccumulatedDuration(shiftRecords)
-			if duration <= 0 {
-				continue
-			}
-
-			var totalOutputRate float64
-			for _, r := range shiftRecords {
-				totalOutputRate += r.OutputRate
-			}
-			avgOutputRate := totalOutputRate / float64(len(shiftRecords))
-			totalProduction := avgOutputRate * (duration / 60)
-
-			var totalPower float64
-			for _, r := range shiftRecords {
-				P1 := r.UnderwaterPumpSuctionVacuum
-				P2 := r.IntermediatePressure
-				P3 := r.BoosterPumpDischargePressure
-				Q := r.FlowRate
-
-				pw1 := 0.8 * Q * (P2 - P1)
-				pw2 := 0.8 * Q * (P3 - P2)
-				totalPower += pw1 + pw2
-			}
-			avgPower := totalPower / float64(len(shiftRecords))
-			totalEnergy := avgPower * (duration / 60)
-
-			pies = append(pies, &ShiftPie{
-				ShiftName: shiftName(shift),
-				WorkData: &PieData{
-					TotalProduction: round(totalProduction),
-					TotalEnergy:     round(totalEnergy),
-					WorkDuration:    duration,
-				},
-			})
+		if dimension == DimensionCrew {
+			pie.Crew = key
+		} else {
+			pie.ShiftName = key
 		}
+		pies = append(pies, pie)
 	}
 
 	return pies, nil
 }
 
 func (s *Service) GetColumnDataList(columnName, shipName string, startTime, endTime int64) ([]*ColumnData, error) {
-	var (
-		tableName string
-		cols      = []string{"record_time"}
-	)
-	if strings.Contains(shipName, "华安龙") {
-		hl := model.DredgerDataHl{}
-		tableName = hl.TableName()
-	} else {
-		if columnName == "hourly_output_rate" {
-			columnName = "current_shift_output_rate"
-		}
-		ml := model.DredgerDatum{}
-		tableName = ml.TableName()
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	// 产量率曲线按统一视图读取该船实际使用的产量率列
+	if columnName == "hourly_output_rate" {
+		columnName = ship.view.column("outputRate")
 	}
-	cols = append(cols, columnName)
+	cols := []string{"record_time", columnName}
 
 	var records []map[string]interface{}
-	err := s.db.Table(tableName).
+	err = s.db.Model(ship.dataModel()).
 		Select(cols).
 		Where("ship_name = ?", shipName).
 		Where("record_time BETWEEN ? AND ?", startTime, endTime).Scan(&records).Error
@@ -991,9 +674,10 @@ func (s *Service) GetColumnDataList(columnName, shipName string, startTime, endT
 		return nil, err
 	}
 
+	loc := ShipLocation(shipName)
 	var dataList []*ColumnData
 	for _, record := range records {
-		t := time.UnixMilli(record["record_time"].(int64)).Format(time.DateTime)
+		t := time.UnixMilli(record["record_time"].(int64)).In(loc).Format(time.DateTime)
 		v := record[columnName]
 		var roundVal float64
 		if val, ok := v.(float64); ok {
@@ -1024,8 +708,9 @@ func (s *Service) GetGlobalTimeRange() ([]*GlobalTimeRange, error) {
 	}
 
 	for _, record := range records {
-		record.StartDateStr = time.UnixMilli(record.StartDate).Format(time.DateOnly)
-		record.EndDateStr = time.UnixMilli(record.EndDate).Format(time.DateOnly)
+		loc := ShipLocation(record.ShipName)
+		record.StartDateStr = time.UnixMilli(record.StartDate).In(loc).Format(time.DateOnly)
+		record.EndDateStr = time.UnixMilli(record.EndDate).In(loc).Format(time.DateOnly)
 	}
 
 	return records, nil
@@ -1096,114 +781,33 @@ func (s *Service) GetTheoryOptimalParams(shipName string) (*TheoryOptimalParamsD
 }
 
 func (s *Service) GetAllShiftParameters(shipName string, startTime, endTime int64) ([]*ShiftWorkParams, error) {
-	var allShiftParams []*ShiftWorkParams
-	var err error
-
-	if strings.Contains(shipName, "华安龙") {
-		columns := []string{
-			"ship_name", "record_time", "hourly_output_rate",
-			// 统计参数
-			"transverse_speed", "trolley_travel", "bridge_depth", "underwater_pump_speed",
-			"concentration", "flow_rate", "underwater_pump_discharge_pressure",
-			// 横移速度回算
-			"cutter_x", "cutter_y",
-			// 真空度平均需要（HL -> Datum 映射）
-			"water_density", "density", "field_slurry_density", "flow_velocity",
-			"ear_draft", "left_ear_draft", "right_ear_draft",
-			// 能量统计（原逻辑）
-			"underwater_pump_power", "mud_pump_1_power", "mud_pump_2_power",
-		}
-		var records []*model.DredgerDataHl
-		err = s.db.Select(columns).Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[华安龙]查询所有班组参数数据失败: %v", err)
-			return nil, err
-		}
-
-		// 按班组（小时）进行分组
-		groups := make(map[int][]*model.DredgerDataHl)
-		for _, record := range records {
-			hour := time.UnixMilli(record.RecordTime).Hour()
-			switch {
-			case hour >= 0 && hour < 6:
-				groups[1] = append(groups[1], record)
-			case hour >= 6 && hour < 12:
-				groups[2] = append(groups[2], record)
-			case hour >= 12 && hour < 18:
-				groups[3] = append(groups[3], record)
-			default:
-				groups[4] = append(groups[4], record)
-			}
-		}
-
-		// 为每个班组计算参数
-		for shift := 1; shift <= 4; shift++ {
-			shiftRecords, exists := groups[shift]
-			if !exists || len(shiftRecords) == 0 {
-				continue
-			}
-
-			p, _ := calParamsHl(shiftRecords)
-			params := &ShiftWorkParams{
-				ShiftName:  shiftName(shift),
-				Parameters: p, // 调用 tool.go 中的现有函数
-			}
-			allShiftParams = append(allShiftParams, params)
-		}
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	// 班次按船舶登记的班次安排在船舶所在时区划分
+	loc := ShipLocation(shipName)
 
-	} else {
-		columns := []string{
-			"ship_name", "record_time", "output_rate",
-			// 统计参数
-			"transverse_speed", "trolley_travel", "cutter_depth", "underwater_pump_speed",
-			"concentration", "flow_rate", "booster_pump_discharge_pressure",
-			// 能量统计 P1/P2/P3/Q
-			"underwater_pump_suction_vacuum", "intermediate_pressure",
-			// 真空度公式需要
-			"water_density", "density", "field_slurry_density", "flow_velocity", "mud_pipe_diameter",
-			"ear_draft", "left_ear_draft", "right_ear_draft", "ear_to_bottom_distance",
-			// 横移速度回算
-			"cutter_x", "cutter_y",
-		}
-		var records []*model.DredgerDatum
-		err = s.db.Select(columns).Where("ship_name = ?", shipName).
-			Where("record_time BETWEEN ? AND ?", startTime, endTime).
-			Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[敏龙]查询所有班组参数数据失败: %v", err)
-			return nil, err
-		}
+	records, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询所有班组参数数据失败: %v", shipName, err)
+		return nil, err
+	}
 
-		groups := make(map[int][]*model.DredgerDatum)
-		for _, record := range records {
-			hour := time.UnixMilli(record.RecordTime).Hour()
-			switch {
-			case hour >= 0 && hour < 6:
-				groups[1] = append(groups[1], record)
-			case hour >= 6 && hour < 12:
-				groups[2] = append(groups[2], record)
-			case hour >= 12 && hour < 18:
-				groups[3] = append(groups[3], record)
-			default:
-				groups[4] = append(groups[4], record)
-			}
+	// 按班组进行分组，为每个班组计算参数
+	var allShiftParams []*ShiftWorkParams
+	shifts, groups := groupByShift(ship, loc, records)
+	for _, shift := range shifts {
+		shiftRecords := groups[shift]
+		if len(shiftRecords) == 0 {
+			continue
 		}
 
-		for shift := 1; shift <= 4; shift++ {
-			shiftRecords, exists := groups[shift]
-			if !exists || len(shiftRecords) == 0 {
-				continue
-			}
-
-			p, _ := calParams(shiftRecords)
-			params := &ShiftWorkParams{
-				ShiftName:  shiftName(shift),
-				Parameters: p, // 调用 tool.go 中的现有函数
-			}
-			allShiftParams = append(allShiftParams, params)
-		}
+		p, _ := calParams(shiftRecords, ship.Hydraulics)
+		allShiftParams = append(allShiftParams, &ShiftWorkParams{
+			ShiftName:  shift,
+			Parameters: p,
+		})
 	}
 
 	return allShiftParams, nil
@@ -1552,139 +1156,52 @@ func (s *Service) OpenLocation(filePath string) error {
 }
 
 func (s *Service) GetPlaybackData(shipName string) (*PlaybackData, error) {
-	cfg := GetCfg(shipName)
-	var estimatedVacuum float64
-
-	if strings.Contains(shipName, "华安龙") {
-		requiredColumns := []string{
-			"record_time", "underwater_pump_suction_vacuum", "flow_rate", "concentration",
-			"underwater_pump_speed", "bridge_depth", "trolley_travel", "transverse_speed",
-			"underwater_pump_discharge_pressure", "hourly_output_rate", "current_shift_output_rate", "flow_velocity", "density",
-			// 以下为计算所需字段
-			"water_density", "field_slurry_density", "ear_draft", "left_ear_draft", "right_ear_draft",
-		}
-
-		var records []*model.DredgerDataHl
-		err := s.db.Select(requiredColumns).Order("record_time asc").Where("ship_name = ?", shipName).Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[华安龙]查询回放数据失败: %v", err)
-			return nil, err
-		}
-
-		result := &PlaybackData{
-			Timestamps:                   make([]int64, 0, len(records)),
-			ActualVacuum:                 make([]float64, 0, len(records)),
-			EstimatedVacuum:              make([]float64, 0, len(records)),
-			FlowRate:                     make([]float64, 0, len(records)),
-			Concentration:                make([]float64, 0, len(records)),
-			SubmergedPumpRpm:             make([]float64, 0, len(records)),
-			LadderDepth:                  make([]float64, 0, len(records)),
-			CarriageTravel:               make([]float64, 0, len(records)),
-			TransverseSpeed:              make([]float64, 0, len(records)),
-			BoosterPumpDischargePressure: make([]float64, 0, len(records)),
-			ProductionRate:               make([]float64, 0, len(records)),
-			FlowVelocity:                 make([]float64, 0, len(records)),
-			Density:                      make([]float64, 0, len(records)),
-		}
-
-		for _, r := range records {
-			datumForCalc := &model.DredgerDatum{
-				WaterDensity:        r.WaterDensity,
-				Density:             r.Density,
-				FieldSlurryDensity:  r.FieldSlurryDensity,
-				MudPipeDiameter:     0.7,  // 泥管直径使用敏龙的数据
-				EarToBottomDistance: 12.9, // 耳轴到船底距离由敏龙的耳轴吃水➗耳轴到船底的距离计算出来的比例，✖️华龙的耳轴吃水
-				FlowVelocity:        r.FlowVelocity,
-				FlowRate:            r.FlowRate,
-				CutterDepth:         r.BridgeDepth,
-				EarDraft:            r.EarDraft,
-				LeftEarDraft:        r.LeftEarDraft,
-				RightEarDraft:       r.RightEarDraft,
-			}
-			estimatedVacuum = calcVacuumKPa(datumForCalc, cfg)
-			if math.IsNaN(estimatedVacuum) || math.IsInf(estimatedVacuum, 0) {
-				estimatedVacuum = 0
-			}
-
-			result.Timestamps = append(result.Timestamps, r.RecordTime)
-			result.ActualVacuum = append(result.ActualVacuum, r.UnderwaterPumpSuctionVacuum)
-			result.EstimatedVacuum = append(result.EstimatedVacuum, estimatedVacuum/100)
-			result.FlowRate = append(result.FlowRate, r.FlowRate)
-			result.Concentration = append(result.Concentration, r.Concentration)
-			result.SubmergedPumpRpm = append(result.SubmergedPumpRpm, r.UnderwaterPumpSpeed)
-			// --- 字段映射修正 ---
-			result.LadderDepth = append(result.LadderDepth, r.BridgeDepth)         // 绞刀深度 -> 桥架深度
-			result.CarriageTravel = append(result.CarriageTravel, r.TrolleyTravel) // 台车行程
-			result.TransverseSpeed = append(result.TransverseSpeed, r.TransverseSpeed)
-			var pumpPressure float64
-			if r.MudPump2DischargePressure != 0 {
-				pumpPressure = r.MudPump2DischargePressure
-			} else if r.MudPump1DischargePressure != 0 {
-				pumpPressure = r.MudPump1DischargePressure
-			} else if r.UnderwaterPumpDischargePressure != 0 {
-				pumpPressure = r.UnderwaterPumpDischargePressure
-			}
-			result.BoosterPumpDischargePressure = append(result.BoosterPumpDischargePressure, pumpPressure)
-			result.ProductionRate = append(result.ProductionRate, r.HourlyOutputRate) // 小时产量率
-			result.FlowVelocity = append(result.FlowVelocity, r.FlowVelocity)
-			result.Density = append(result.Density, r.Density)
-		}
-		return result, nil
-
-	} else {
-		requiredColumns := []string{
-			"record_time", "underwater_pump_suction_vacuum", "flow_rate", "concentration",
-			"underwater_pump_speed", "cutter_depth", "trolley_travel", "transverse_speed",
-			"booster_pump_discharge_pressure", "hourly_output_rate", "current_shift_output_rate", "flow_velocity", "density", // <-- 修正: 从 output_rate 改为 hourly_output_rate
-			// 以下为计算所需字段
-			"water_density", "field_slurry_density", "mud_pipe_diameter", "ear_draft",
-			"left_ear_draft", "right_ear_draft", "ear_to_bottom_distance",
-		}
-		var records []*model.DredgerDatum
-		err := s.db.Select(requiredColumns).Order("record_time asc").Where("ship_name = ?", shipName).Find(&records).Error
-		if err != nil {
-			logger.Logger.Errorf("[敏龙]查询回放数据失败: %v", err)
-			return nil, err
-		}
-
-		result := &PlaybackData{
-			Timestamps:                   make([]int64, 0, len(records)),
-			ActualVacuum:                 make([]float64, 0, len(records)),
-			EstimatedVacuum:              make([]float64, 0, len(records)),
-			FlowRate:                     make([]float64, 0, len(records)),
-			Concentration:                make([]float64, 0, len(records)),
-			SubmergedPumpRpm:             make([]float64, 0, len(records)),
-			LadderDepth:                  make([]float64, 0, len(records)),
-			CarriageTravel:               make([]float64, 0, len(records)),
-			TransverseSpeed:              make([]float64, 0, len(records)),
-			BoosterPumpDischargePressure: make([]float64, 0, len(records)),
-			ProductionRate:               make([]float64, 0, len(records)),
-			FlowVelocity:                 make([]float64, 0, len(records)),
-			Density:                      make([]float64, 0, len(records)),
-		}
-
-		for _, r := range records {
-			estimatedVacuum = calcVacuumKPa(r, cfg)
-			if math.IsNaN(estimatedVacuum) || math.IsInf(estimatedVacuum, 0) {
-				estimatedVacuum = 0
-			}
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
 
-			result.Timestamps = append(result.Timestamps, r.RecordTime)
-			result.ActualVacuum = append(result.ActualVacuum, r.UnderwaterPumpSuctionVacuum)
-			result.EstimatedVacuum = append(result.EstimatedVacuum, estimatedVacuum/100)
-			result.FlowRate = append(result.FlowRate, r.FlowRate)
-			result.Concentration = append(result.Concentration, r.Concentration)
-			result.SubmergedPumpRpm = append(result.SubmergedPumpRpm, r.UnderwaterPumpSpeed)
-			// --- 字段映射修正 ---
-			result.LadderDepth = append(result.LadderDepth, r.CutterDepth)
-			result.CarriageTravel = append(result.CarriageTravel, r.TrolleyTravel)
-			result.TransverseSpeed = append(result.TransverseSpeed, r.TransverseSpeed)
-			result.BoosterPumpDischargePressure = append(result.BoosterPumpDischargePressure, r.BoosterPumpDischargePressure)
-			result.ProductionRate = append(result.ProductionRate, r.CurrentShiftOutputRate)
-			result.FlowVelocity = append(result.FlowVelocity, r.FlowVelocity)
-			result.Density = append(result.Density, r.Density)
-		}
-		return result, nil
+	records, err := loadRecords(s.db, ship, 0, math.MaxInt64)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询回放数据失败: %v", shipName, err)
+		return nil, err
+	}
 
+	result := &PlaybackData{
+		Timestamps:                   make([]int64, 0, len(records)),
+		ActualVacuum:                 make([]float64, 0, len(records)),
+		EstimatedVacuum:              make([]float64, 0, len(records)),
+		FlowRate:                     make([]float64, 0, len(records)),
+		Concentration:                make([]float64, 0, len(records)),
+		SubmergedPumpRpm:             make([]float64, 0, len(records)),
+		LadderDepth:                  make([]float64, 0, len(records)),
+		CarriageTravel:               make([]float64, 0, len(records)),
+		TransverseSpeed:              make([]float64, 0, len(records)),
+		BoosterPumpDischargePressure: make([]float64, 0, len(records)),
+		ProductionRate:               make([]float64, 0, len(records)),
+		FlowVelocity:                 make([]float64, 0, len(records)),
+		Density:                      make([]float64, 0, len(records)),
+	}
+
+	for _, r := range records {
+		estimatedVacuum := calcVacuumKPa(r, ship.Hydraulics)
+		if math.IsNaN(estimatedVacuum) || math.IsInf(estimatedVacuum, 0) {
+			estimatedVacuum = 0
+		}
+
+		result.Timestamps = append(result.Timestamps, r.RecordTime)
+		result.ActualVacuum = append(result.ActualVacuum, r.SuctionVacuum)
+		result.EstimatedVacuum = append(result.EstimatedVacuum, estimatedVacuum/100)
+		result.FlowRate = append(result.FlowRate, r.FlowRate)
+		result.Concentration = append(result.Concentration, r.Concentration)
+		result.SubmergedPumpRpm = append(result.SubmergedPumpRpm, r.PumpSpeed)
+		result.LadderDepth = append(result.LadderDepth, r.CutterDepth)
+		result.CarriageTravel = append(result.CarriageTravel, r.TrolleyTravel)
+		result.TransverseSpeed = append(result.TransverseSpeed, r.TransverseSpeed)
+		result.BoosterPumpDischargePressure = append(result.BoosterPumpDischargePressure, r.DischargePressure)
+		result.ProductionRate = append(result.ProductionRate, r.OutputRate)
+		result.FlowVelocity = append(result.FlowVelocity, r.FlowVelocity)
+		result.Density = append(result.Density, r.Density)
 	}
+	return result, nil
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportBucketsClassify(t *testing.T) {
+	// 库中已有时间 3 和 5；文件分两个批次，时间 2 在文件内重复（跨批次），时间 4 在同一批次内重复
+	existing := map[int64]struct{}{3: {}, 5: {}}
+	batches := [][]int64{{1, 2, 3}, {2, 4, 4, 5}}
+
+	tests := []struct {
+		mode         string
+		want         ImportBuckets
+		wantWrites   [][]int
+		wantConflict []int
+	}{
+		{
+			mode:         ImportModeSkip,
+			want:         ImportBuckets{Inserted: 3, Unchanged: 4},
+			wantWrites:   [][]int{{0, 1}, {1}},
+			wantConflict: []int{-1, -1},
+		},
+		{
+			mode:         ImportModeOverwrite,
+			want:         ImportBuckets{Inserted: 3, Overwritten: 4},
+			wantWrites:   [][]int{{0, 1, 2}, {0, 1, 2, 3}},
+			wantConflict: []int{-1, -1},
+		},
+		{
+			mode:         ImportModeFail,
+			want:         ImportBuckets{Inserted: 3, Conflicts: 4},
+			wantWrites:   [][]int{{0, 1}, {1}},
+			wantConflict: []int{2, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var buckets ImportBuckets
+			seen := make(map[int64]struct{})
+			for i, timestamps := range batches {
+				writes, conflict := buckets.classify(timestamps, existing, seen, tt.mode)
+				if !reflect.DeepEqual(writes, tt.wantWrites[i]) || conflict != tt.wantConflict[i] {
+					t.Errorf("批次 %d classify() = %v, %d, want %v, %d", i, writes, conflict, tt.wantWrites[i], tt.wantConflict[i])
+				}
+			}
+			if buckets != tt.want {
+				t.Errorf("buckets = %+v, want %+v", buckets, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"dredger/pkg/logger"
+	"sort"
+	"time"
+)
+
+// 班组统计的时间粒度
+const (
+	GranularityAggregate = "aggregate" // 整个时间段合并
+	GranularityDay       = "day"       // 按天
+	GranularityWeek      = "week"      // 按周，周一开始
+)
+
+// newShiftStat 统计一个班组的记录，没有施工时长时返回 nil
+func newShiftStat(key, dimension string, records []*record, soil *soilClassifier, loc *time.Location, ship *ShipConfig) (*ShiftStat, workload) {
+	work := measureWorkload(records, loc, ship.Duration)
+	if work.Duration <= 0 {
+		return nil, work
+	}
+	minTime, maxTime := recordSpan(records)
+
+	// 计算当前班组遇到的所有土质
+	soilTypesMap := make(map[string]struct{})
+	for _, r := range records {
+		soilTypesMap[soil.soilType(r)] = struct{}{}
+	}
+	var soilTypes []string
+	for soil := range soilTypesMap {
+		soilTypes = append(soilTypes, soil)
+	}
+	sort.Strings(soilTypes) // 保证顺序一致
+
+	stat := &ShiftStat{
+		BeginTime:           minTime,
+		EndTime:             maxTime,
+		WorkDuration:        work.Duration,
+		TotalProduction:     round(work.Production),
+		TotalEnergy:         round(work.UnitEnergy()),
+		SoilTypes:           soilTypes,
+		EnergyKWh:           energyKWh(work.Energy, ship.Energy.energyUnit()),
+		EnergyUnit:          ship.Energy.energyUnit(),
+		EstimatedProduction: round(work.EstimatedProduction),
+		TotalFuel:           round(work.Fuel),
+		GapCount:            len(work.Gaps),
+	}
+	for _, gap := range work.Gaps {
+		stat.GapDuration += gap.Duration
+	}
+	if dimension == DimensionCrew {
+		stat.Crew = key
+	} else {
+		stat.ShiftName = key
+	}
+	return stat, work
+}
+
+// periodOf 返回记录按粒度所在的周期：按天为班次开始的日期，按周为该周的周一，合并时为空
+func periodOf(r *record, granularity string) string {
+	switch granularity {
+	case GranularityDay:
+		return r.ShiftDate
+	case GranularityWeek:
+		date, err := time.Parse(time.DateOnly, r.ShiftDate)
+		if err != nil {
+			return r.ShiftDate
+		}
+		weekday := (int(date.Weekday()) + 6) % 7 // 周一为 0
+		return date.AddDate(0, 0, -weekday).Format(time.DateOnly)
+	}
+	return ""
+}
+
+// shiftStatTotal 累加各周期的统计，生成合计和平均值
+type shiftStatTotal struct {
+	summary ShiftStatSummary
+	periods map[string]bool
+	work    workload
+}
+
+func (t *shiftStatTotal) add(period string, work workload) {
+	if t.periods == nil {
+		t.periods = make(map[string]bool)
+	}
+	t.periods[period] = true
+	t.work.Duration += work.Duration
+	t.work.Production += work.Production
+	t.work.Energy += work.Energy
+	t.work.EstimatedProduction += work.EstimatedProduction
+	t.work.Fuel += work.Fuel
+}
+
+func (t *shiftStatTotal) result() *ShiftStatSummary {
+	s := t.summary
+	s.Periods = len(t.periods)
+	s.WorkDuration = t.work.Duration
+	s.TotalProduction = round(t.work.Production)
+	s.TotalEnergy = round(t.work.UnitEnergy())
+	s.EnergyKWh = energyKWh(t.work.Energy, s.EnergyUnit)
+	s.EstimatedProduction = round(t.work.EstimatedProduction)
+	s.TotalFuel = round(t.work.Fuel)
+	if s.Periods > 0 {
+		s.AvgWorkDuration = round(t.work.Duration / float64(s.Periods))
+		s.AvgProduction = round(t.work.Production / float64(s.Periods))
+	}
+	return &s
+}
+
+// GetShiftStatSeries 按天或按周统计各班组，dimension 为 crew 时按值班班组，否则按班次；
+// 跨越午夜的班次计入开始的那一天。除各周期的统计外，还返回每个班组和全部班组的合计与平均值
+func (s *Service) GetShiftStatSeries(shipName string, startTime, endTime int64, dimension, granularity string) (*ShiftStatSeries, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	loc := ShipLocation(shipName)
+	if granularity == "" {
+		granularity = GranularityAggregate
+	}
+
+	soil, err := s.loadSoilClassifier(ship)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询班组统计数据失败: %v", shipName, err)
+		return nil, err
+	}
+
+	keys, groups, err := s.groupRecords(ship, loc, records, startTime, endTime, dimension)
+	if err != nil {
+		logger.Logger.Errorf("[%s]%v", shipName, err)
+		return nil, err
+	}
+
+	series := &ShiftStatSeries{Granularity: granularity, Items: []*ShiftStat{}, Summaries: []*ShiftStatSummary{}}
+	periods := make(map[string]bool)
+	total := shiftStatTotal{summary: ShiftStatSummary{EnergyUnit: ship.Energy.energyUnit()}}
+	for _, key := range keys {
+		byPeriod := make(map[string][]*record)
+		for _, r := range groups[key] {
+			period := periodOf(r, granularity)
+			byPeriod[period] = append(byPeriod[period], r)
+		}
+
+		sum := shiftStatTotal{summary: ShiftStatSummary{EnergyUnit: ship.Energy.energyUnit()}}
+		if dimension == DimensionCrew {
+			sum.summary.Crew = key
+		} else {
+			sum.summary.ShiftName = key
+		}
+		for period, periodRecords := range byPeriod {
+			stat, work := newShiftStat(key, dimension, periodRecords, soil, loc, ship)
+			if stat == nil {
+				continue
+			}
+			stat.Period = period
+			series.Items = append(series.Items, stat)
+			periods[period] = true
+			sum.add(period, work)
+			total.add(period, work)
+		}
+		if len(sum.periods) > 0 {
+			series.Summaries = append(series.Summaries, sum.result())
+		}
+	}
+	series.Total = total.result()
+
+	for period := range periods {
+		series.Periods = append(series.Periods, period)
+	}
+	sort.Strings(series.Periods)
+	order := make(map[string]int, len(keys))
+	for i, key := range keys {
+		order[key] = i
+	}
+	sort.Slice(series.Items, func(i, j int) bool {
+		a, b := series.Items[i], series.Items[j]
+		if a.Period != b.Period {
+			return a.Period < b.Period
+		}
+		return order[a.ShiftName+a.Crew] < order[b.ShiftName+b.Crew]
+	})
+	return series, nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPeriodOf(t *testing.T) {
+	tests := []struct {
+		date        string
+		granularity string
+		want        string
+	}{
+		{date: "2026-03-10", granularity: GranularityAggregate, want: ""},
+		{date: "2026-03-10", granularity: GranularityDay, want: "2026-03-10"},
+		{date: "2026-03-09", granularity: GranularityWeek, want: "2026-03-09"}, // 周一
+		{date: "2026-03-10", granularity: GranularityWeek, want: "2026-03-09"},
+		{date: "2026-03-15", granularity: GranularityWeek, want: "2026-03-09"}, // 周日属于前一个周一
+		{date: "2026-03-16", granularity: GranularityWeek, want: "2026-03-16"},
+		{date: "2026-01-01", granularity: GranularityWeek, want: "2025-12-29"}, // 跨年
+	}
+
+	for _, tt := range tests {
+		if got := periodOf(&record{ShiftDate: tt.date}, tt.granularity); got != tt.want {
+			t.Errorf("periodOf(%s, %s) = %q, want %q", tt.date, tt.granularity, got, tt.want)
+		}
+	}
+}
+
+func TestShiftStatTotal(t *testing.T) {
+	total := shiftStatTotal{summary: ShiftStatSummary{EnergyUnit: EnergyUnitKWh}}
+	// 同一周的两天计为一个周期
+	for _, date := range []string{"2026-03-10", "2026-03-12", "2026-03-16"} {
+		period := periodOf(&record{ShiftDate: date}, GranularityWeek)
+		total.add(period, workload{Duration: 120, Production: 300, EstimatedProduction: 330, Energy: 600, Fuel: 90})
+	}
+
+	energy := 1800.0
+	want := &ShiftStatSummary{
+		Periods:             2,
+		WorkDuration:        360,
+		TotalProduction:     900,
+		TotalEnergy:         2,
+		EnergyKWh:           &energy,
+		EnergyUnit:          EnergyUnitKWh,
+		EstimatedProduction: 990,
+		TotalFuel:           270,
+		AvgWorkDuration:     180,
+		AvgProduction:       450,
+	}
+	if got := total.result(); !reflect.DeepEqual(got, want) {
+		t.Errorf("result() = %+v, want %+v", got, want)
+	}
+
+	empty := shiftStatTotal{summary: ShiftStatSummary{EnergyUnit: EnergyUnitRelative}}
+	if got := empty.result(); got.Periods != 0 || got.AvgWorkDuration != 0 || got.EnergyKWh != nil {
+		t.Errorf("没有周期时 result() = %+v", got)
+	}
+}
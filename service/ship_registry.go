@@ -0,0 +1,566 @@
+package service
+
+import (
+	"dredger/model"
+	"dredger/pkg/database"
+	"dredger/pkg/logger"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 施工数据结构，决定数据存放的表和字段
+const (
+	DataSchemaHL = "hl" // dredger_data_hl，华安龙的传感器字段
+	DataSchemaML = "ml" // dredger_data，敏龙的传感器字段
+)
+
+// 土质模型
+const (
+	SoilModelNone    = "none"    // 不区分土质
+	SoilModelRegions = "regions" // 按 soil_regions 中的土质区域分组
+)
+
+var (
+	ErrShipNotRegistered = errors.New("船舶未登记")
+	ErrShipHasData       = errors.New("船舶已有施工数据")
+)
+
+// shipRegistry 缓存 ships 表中的登记信息，首次查询时加载，修改后失效
+type shipRegistry struct {
+	mu    sync.RWMutex
+	db    *gorm.DB
+	ships map[string]*ShipConfig // nil 表示尚未加载
+}
+
+var registry shipRegistry
+
+// bind 设置加载登记信息使用的数据库连接，只在第一次调用时生效
+func (r *shipRegistry) bind(db *gorm.DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.db == nil {
+		r.db = db
+	}
+}
+
+func (r *shipRegistry) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ships = nil
+}
+
+// all 返回所有登记的船舶，必要时从数据库加载
+func (r *shipRegistry) all() (map[string]*ShipConfig, error) {
+	r.mu.RLock()
+	ships := r.ships
+	r.mu.RUnlock()
+	if ships != nil {
+		return ships, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ships != nil {
+		return r.ships, nil
+	}
+	if r.db == nil {
+		return nil, errors.New("船舶登记表未初始化")
+	}
+	var rows []*model.Ship
+	if err := r.db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("加载船舶登记信息失败: %v", err)
+	}
+	ships = make(map[string]*ShipConfig, len(rows))
+	for _, row := range rows {
+		ship, err := shipFromModel(row)
+		if err != nil {
+			// 单条登记信息损坏不影响其它船舶
+			logger.Logger.Errorf("船舶 %s 的登记信息有误: %v", row.Name, err)
+			continue
+		}
+		ships[ship.Name] = ship
+	}
+	r.ships = ships
+	return ships, nil
+}
+
+// lookupShip 返回船舶的登记信息；返回值为缓存，调用方不能修改
+func lookupShip(shipName string) (*ShipConfig, error) {
+	ships, err := registry.all()
+	if err != nil {
+		return nil, err
+	}
+	ship, ok := ships[shipName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrShipNotRegistered, shipName)
+	}
+	return ship, nil
+}
+
+// LookupShip 返回船舶的登记信息副本
+func LookupShip(shipName string) (*ShipConfig, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	return ship.clone(), nil
+}
+
+func (c *ShipConfig) clone() *ShipConfig {
+	ship := *c
+	ship.ShiftSchedules = make([]ShiftSchedule, len(c.ShiftSchedules))
+	for i, schedule := range c.ShiftSchedules {
+		schedule.Shifts = append([]ShipShift(nil), schedule.Shifts...)
+		ship.ShiftSchedules[i] = schedule
+	}
+	if c.SensorPoints != nil {
+		points := make(map[string]int, len(c.SensorPoints.Points))
+		for column, point := range c.SensorPoints.Points {
+			points[column] = point
+		}
+		ship.SensorPoints = &ShipSensorPoints{Base: c.SensorPoints.Base, Points: points}
+	}
+	ship.Energy.Stages = append([]EnergyStage(nil), c.Energy.Stages...)
+	ship.Energy.PowerColumns = append([]string(nil), c.Energy.PowerColumns...)
+	ship.Energy.Engines = make([]DieselEngine, len(c.Energy.Engines))
+	for i, engine := range c.Energy.Engines {
+		engine.Curve = append([]DieselCurvePoint(nil), engine.Curve...)
+		ship.Energy.Engines[i] = engine
+	}
+	if c.CrewRotations != nil {
+		ship.CrewRotations = make([]CrewRotation, len(c.CrewRotations))
+		for i, rotation := range c.CrewRotations {
+			pattern := make([]map[string]string, len(rotation.Pattern))
+			for day, crews := range rotation.Pattern {
+				pattern[day] = make(map[string]string, len(crews))
+				for shift, crew := range crews {
+					pattern[day][shift] = crew
+				}
+			}
+			ship.CrewRotations[i] = CrewRotation{StartDate: rotation.StartDate, Pattern: pattern}
+		}
+	}
+	if c.Fields != nil {
+		ship.Fields = make(map[string]RecordField, len(c.Fields))
+		for name, f := range c.Fields {
+			f.Columns = append([]string(nil), f.Columns...)
+			ship.Fields[name] = f
+		}
+	}
+	return &ship
+}
+
+// dataModel 返回存放该船施工数据的模型
+func (c *ShipConfig) dataModel() any {
+	if c.DataSchema == DataSchemaHL {
+		return &model.DredgerDataHl{}
+	}
+	return &model.DredgerDatum{}
+}
+
+// dataModelType 返回施工数据模型的结构体类型
+func (c *ShipConfig) dataModelType() reflect.Type {
+	return reflect.TypeOf(c.dataModel()).Elem()
+}
+
+// normalize 补全默认值并校验登记信息
+func (c *ShipConfig) normalize() error {
+	c.Name = strings.TrimSpace(c.Name)
+	if c.Name == "" {
+		return errors.New("船名不能为空")
+	}
+	switch c.DataSchema {
+	case DataSchemaHL, DataSchemaML:
+	default:
+		return fmt.Errorf("不支持的数据结构 %q，应为 %s 或 %s", c.DataSchema, DataSchemaHL, DataSchemaML)
+	}
+	switch c.SoilModel {
+	case "":
+		c.SoilModel = SoilModelNone
+	case SoilModelNone, SoilModelRegions:
+	default:
+		return fmt.Errorf("不支持的土质模型 %q，应为 %s 或 %s", c.SoilModel, SoilModelNone, SoilModelRegions)
+	}
+	if err := c.SoilCoordinates.normalize(); err != nil {
+		return err
+	}
+	if c.Timezone != "" {
+		if _, err := time.LoadLocation(c.Timezone); err != nil {
+			return fmt.Errorf("无法识别的时区 %q", c.Timezone)
+		}
+	}
+	if c.Hydraulics == (ShipHydraulicsConfig{}) {
+		c.Hydraulics = defaultHydraulicsConfig()
+	}
+
+	if err := c.Duration.normalize(); err != nil {
+		return err
+	}
+	states, err := c.States.normalize()
+	if err != nil {
+		return err
+	}
+	c.States = states
+	if err := c.normalizeShiftSchedules(); err != nil {
+		return err
+	}
+	if err := c.normalizeCrewRotations(); err != nil {
+		return err
+	}
+
+	if err := c.Energy.normalize(c.DataSchema); err != nil {
+		return err
+	}
+	view, err := newRecordView(c.dataModelType(), c.DataSchema, c.Fields, c.Energy)
+	if err != nil {
+		return err
+	}
+	c.view = view
+
+	if c.SensorPoints != nil {
+		if len(c.SensorPoints.Points) == 0 {
+			c.SensorPoints = nil
+			return nil
+		}
+		columns := sensorColumns(c.dataModelType())
+		for column, point := range c.SensorPoints.Points {
+			if _, ok := columns[column]; !ok {
+				return fmt.Errorf("传感器点位中的列 %s 不是 %s 数据结构的数值列", column, c.DataSchema)
+			}
+			if point < c.SensorPoints.Base {
+				return fmt.Errorf("列 %s 的点位 %d 小于起始点位 %d", column, point, c.SensorPoints.Base)
+			}
+		}
+	}
+	return nil
+}
+
+// floatColumns 返回模型中的浮点数列，列名 => 字段下标
+func floatColumns(modelType reflect.Type) map[string]int {
+	columns := make(map[string]int)
+	for _, c := range importableColumns(modelType) {
+		if modelType.Field(c.field).Type.Kind() == reflect.Float64 {
+			columns[c.column] = c.field
+		}
+	}
+	return columns
+}
+
+// sensorColumns 返回模型中可由传感器写入的数值列（浮点数和整数，如横移方向），列名 => 字段下标
+func sensorColumns(modelType reflect.Type) map[string]int {
+	columns := make(map[string]int)
+	for _, c := range importableColumns(modelType) {
+		switch modelType.Field(c.field).Type.Kind() {
+		case reflect.Float64, reflect.Int32, reflect.Int64:
+			columns[c.column] = c.field
+		}
+	}
+	return columns
+}
+
+func (c *ShipConfig) toModel() (*model.Ship, error) {
+	hydraulics, err := json.Marshal(c.Hydraulics)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := json.Marshal(c.ShiftSchedules)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := json.Marshal(c.Duration)
+	if err != nil {
+		return nil, err
+	}
+	states, err := json.Marshal(c.States)
+	if err != nil {
+		return nil, err
+	}
+	energy, err := json.Marshal(c.Energy)
+	if err != nil {
+		return nil, err
+	}
+	soil, err := json.Marshal(c.SoilCoordinates)
+	if err != nil {
+		return nil, err
+	}
+	row := &model.Ship{
+		Name:            c.Name,
+		DataSchema:      c.DataSchema,
+		Timezone:        c.Timezone,
+		Hydraulics:      string(hydraulics),
+		ShiftSchedule:   string(shifts),
+		DurationRule:    string(duration),
+		StateRule:       string(states),
+		EnergyModel:     string(energy),
+		SoilModel:       c.SoilModel,
+		SoilCoordinates: string(soil),
+	}
+	if c.SensorPoints != nil {
+		points, err := json.Marshal(c.SensorPoints)
+		if err != nil {
+			return nil, err
+		}
+		row.SensorPoints = string(points)
+	}
+	if len(c.CrewRotations) > 0 {
+		rotations, err := json.Marshal(c.CrewRotations)
+		if err != nil {
+			return nil, err
+		}
+		row.CrewRotation = string(rotations)
+	}
+	if len(c.Fields) > 0 {
+		fields, err := json.Marshal(c.Fields)
+		if err != nil {
+			return nil, err
+		}
+		row.FieldMapping = string(fields)
+	}
+	return row, nil
+}
+
+func shipFromModel(row *model.Ship) (*ShipConfig, error) {
+	ship := &ShipConfig{
+		Name:       row.Name,
+		DataSchema: row.DataSchema,
+		Timezone:   row.Timezone,
+		SoilModel:  row.SoilModel,
+		CreatedAt:  row.CreatedAt,
+		UpdatedAt:  row.UpdatedAt,
+	}
+	if row.Hydraulics != "" {
+		if err := json.Unmarshal([]byte(row.Hydraulics), &ship.Hydraulics); err != nil {
+			return nil, fmt.Errorf("水力参数: %v", err)
+		}
+	}
+	if row.DurationRule != "" {
+		if err := json.Unmarshal([]byte(row.DurationRule), &ship.Duration); err != nil {
+			return nil, fmt.Errorf("施工时长规则: %v", err)
+		}
+	}
+	if row.EnergyModel != "" {
+		if err := json.Unmarshal([]byte(row.EnergyModel), &ship.Energy); err != nil {
+			return nil, fmt.Errorf("能耗模型: %v", err)
+		}
+	}
+	if row.SoilCoordinates != "" {
+		if err := json.Unmarshal([]byte(row.SoilCoordinates), &ship.SoilCoordinates); err != nil {
+			return nil, fmt.Errorf("土质区域坐标字段: %v", err)
+		}
+	}
+	if row.StateRule != "" {
+		if err := json.Unmarshal([]byte(row.StateRule), &ship.States); err != nil {
+			return nil, fmt.Errorf("运行状态阈值: %v", err)
+		}
+	}
+	if row.ShiftSchedule != "" {
+		if err := json.Unmarshal([]byte(row.ShiftSchedule), &ship.ShiftSchedules); err != nil {
+			return nil, fmt.Errorf("班次: %v", err)
+		}
+	}
+	if row.SensorPoints != "" {
+		ship.SensorPoints = &ShipSensorPoints{}
+		if err := json.Unmarshal([]byte(row.SensorPoints), ship.SensorPoints); err != nil {
+			return nil, fmt.Errorf("传感器点位: %v", err)
+		}
+	}
+	if row.CrewRotation != "" {
+		if err := json.Unmarshal([]byte(row.CrewRotation), &ship.CrewRotations); err != nil {
+			return nil, fmt.Errorf("班组轮换: %v", err)
+		}
+	}
+	if row.FieldMapping != "" {
+		if err := json.Unmarshal([]byte(row.FieldMapping), &ship.Fields); err != nil {
+			return nil, fmt.Errorf("字段映射: %v", err)
+		}
+	}
+	if err := ship.normalize(); err != nil {
+		return nil, err
+	}
+	return ship, nil
+}
+
+// DecodeSensorFrame 按登记的点位把一帧 AI 浮点数写入施工数据模型 dst（指针），返回未收到的列
+func (c *ShipConfig) DecodeSensorFrame(values []float32, dst any) ([]string, error) {
+	if c.SensorPoints == nil {
+		return nil, fmt.Errorf("船舶 %s 未登记传感器点位", c.Name)
+	}
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.Elem().Type() != c.dataModelType() {
+		return nil, fmt.Errorf("船舶 %s 的传感器数据应写入 %s", c.Name, c.dataModelType().Name())
+	}
+	fields := sensorColumns(c.dataModelType())
+
+	var missing []string
+	for column, point := range c.SensorPoints.Points {
+		i := point - c.SensorPoints.Base
+		if i >= len(values) {
+			missing = append(missing, column)
+			continue
+		}
+		field := v.Elem().Field(fields[column])
+		if field.Kind() == reflect.Float64 {
+			field.SetFloat(float64(values[i]))
+		} else {
+			// 整数列按协议截断小数部分
+			field.SetInt(int64(values[i]))
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// EstimateVacuumKPa 按船舶的字段映射和水力参数估算一条施工数据（模型指针）的吸入真空度，单位 kPa
+func (c *ShipConfig) EstimateVacuumKPa(src any) float64 {
+	return calcVacuumKPa(c.view.convert(reflect.Indirect(reflect.ValueOf(src))), c.Hydraulics)
+}
+
+// Migrations 返回依赖业务逻辑的一次性迁移，启动服务和 dredgerctl migrate 时传给 database.Migrate
+func Migrations() []database.Migration {
+	return []database.Migration{
+		// 旧版本按船名是否包含“华安龙”选择数据表，登记已有的船舶
+		{Name: "init_ships_v1", Apply: SeedShips},
+		// 旧版本按文件名中的起止日期写入 data_date，按实际数据重新统计一次
+		{Name: "rebuild_data_date_v1", Apply: func(db *gorm.DB) error {
+			_, err := RebuildDataDates(db, "")
+			return err
+		}},
+	}
+}
+
+// SeedShips 登记已有的船舶，用于从按船名判断数据结构的旧版本升级：
+// 华安龙使用 hl 数据结构和实时传感器点位，敏龙按土质区域分组；
+// 施工数据表中的其它船名按所在的表登记，已登记的船舶不会被修改
+func SeedShips(db *gorm.DB) error {
+	registry.bind(db)
+	seeds := map[string]*ShipConfig{
+		"华安龙": {
+			Name:         "华安龙",
+			DataSchema:   DataSchemaHL,
+			SoilModel:    SoilModelNone,
+			SensorPoints: &ShipSensorPoints{Base: defaultSensorPointBase, Points: defaultHlSensorPoints},
+		},
+		"敏龙": {Name: "敏龙", DataSchema: DataSchemaML, SoilModel: SoilModelRegions},
+	}
+	for schema, table := range map[string]any{DataSchemaHL: &model.DredgerDataHl{}, DataSchemaML: &model.DredgerDatum{}} {
+		var names []string
+		if err := db.Model(table).Distinct().Pluck("ship_name", &names).Error; err != nil {
+			return err
+		}
+		for _, name := range names {
+			if _, ok := seeds[name]; ok || name == "" {
+				continue
+			}
+			ship := &ShipConfig{Name: name, DataSchema: schema, SoilModel: SoilModelNone}
+			if strings.Contains(name, "敏龙") {
+				ship.SoilModel = SoilModelRegions
+			}
+			seeds[name] = ship
+		}
+	}
+
+	for _, ship := range seeds {
+		if err := ship.normalize(); err != nil {
+			return fmt.Errorf("登记船舶 %s 失败: %v", ship.Name, err)
+		}
+		row, err := ship.toModel()
+		if err != nil {
+			return err
+		}
+		if err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(row).Error; err != nil {
+			return fmt.Errorf("登记船舶 %s 失败: %v", ship.Name, err)
+		}
+	}
+	registry.invalidate()
+	return nil
+}
+
+// ListShips 返回所有登记的船舶，按船名排序
+func (s *Service) ListShips() ([]*ShipConfig, error) {
+	ships, err := registry.all()
+	if err != nil {
+		logger.Logger.Errorf("%v", err)
+		return nil, err
+	}
+	list := make([]*ShipConfig, 0, len(ships))
+	for _, ship := range ships {
+		list = append(list, ship.clone())
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// GetShip 返回某船的登记信息
+func (s *Service) GetShip(shipName string) (*ShipConfig, error) {
+	return LookupShip(shipName)
+}
+
+// SaveShip 新增或修改船舶登记信息。已有施工数据的船舶不能修改数据结构
+func (s *Service) SaveShip(ship *ShipConfig) (*ShipConfig, error) {
+	if err := ship.normalize(); err != nil {
+		return nil, err
+	}
+	row, err := ship.toModel()
+	if err != nil {
+		return nil, err
+	}
+
+	if current, err := lookupShip(ship.Name); err == nil && current.DataSchema != ship.DataSchema {
+		var count int64
+		if err = s.db.Model(current.dataModel()).Where("ship_name = ?", ship.Name).Limit(1).Count(&count).Error; err != nil {
+			logger.Logger.Errorf("查询船舶 %s 的施工数据失败: %v", ship.Name, err)
+			return nil, err
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("%w，不能将数据结构从 %s 改为 %s", ErrShipHasData, current.DataSchema, ship.DataSchema)
+		}
+	}
+
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "data_schema", "timezone", "hydraulics", "energy_model", "duration_rule", "state_rule", "shift_schedule", "soil_model", "soil_coordinates", "sensor_points", "field_mapping", "crew_rotation"}),
+	}).Create(row).Error
+	if err != nil {
+		logger.Logger.Errorf("保存船舶 %s 的登记信息失败: %v", ship.Name, err)
+		return nil, err
+	}
+	registry.invalidate()
+	return LookupShip(ship.Name)
+}
+
+// DeleteShip 删除船舶登记信息，已有施工数据的船舶不能删除
+func (s *Service) DeleteShip(shipName string) error {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return err
+	}
+	var count int64
+	if err = s.db.Model(ship.dataModel()).Where("ship_name = ?", shipName).Limit(1).Count(&count).Error; err != nil {
+		logger.Logger.Errorf("查询船舶 %s 的施工数据失败: %v", shipName, err)
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w，不能删除", ErrShipHasData)
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("ship_name = ?", shipName).Delete(&model.CrewAssignment{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("name = ?", shipName).Delete(&model.Ship{}).Error
+	})
+	if err != nil {
+		logger.Logger.Errorf("删除船舶 %s 失败: %v", shipName, err)
+		return err
+	}
+	registry.invalidate()
+	return nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// defaultShipShifts 未配置班次时按 6 小时划分为 4 个班次
+func defaultShipShifts() []ShipShift {
+	return []ShipShift{
+		{Name: "0-6", Start: "00:00", End: "06:00"},
+		{Name: "6-12", Start: "06:00", End: "12:00"},
+		{Name: "12-18", Start: "12:00", End: "18:00"},
+		{Name: "18-24", Start: "18:00", End: "24:00"},
+	}
+}
+
+// parseClock 解析 "HH:MM"，返回当天的分钟数，允许 24:00
+func parseClock(value string) (int, error) {
+	var hour, minute int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hour, &minute); err != nil || len(value) != 5 {
+		return 0, fmt.Errorf("时间格式有误: %q，应为 HH:MM", value)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour*60+minute > minutesPerDay {
+		return 0, fmt.Errorf("时间超出范围: %q", value)
+	}
+	return hour*60 + minute, nil
+}
+
+// normalizeShiftSchedules 补全默认班次，按生效日期排列并校验每套班次安排
+func (c *ShipConfig) normalizeShiftSchedules() error {
+	if len(c.ShiftSchedules) == 0 {
+		c.ShiftSchedules = []ShiftSchedule{{Shifts: defaultShipShifts()}}
+	}
+	for i := range c.ShiftSchedules {
+		schedule := &c.ShiftSchedules[i]
+		schedule.EffectiveFrom = strings.TrimSpace(schedule.EffectiveFrom)
+		if schedule.EffectiveFrom != "" {
+			date, err := time.Parse(time.DateOnly, schedule.EffectiveFrom)
+			if err != nil {
+				return fmt.Errorf("班次安排的生效日期有误: %q，应为 YYYY-MM-DD", schedule.EffectiveFrom)
+			}
+			schedule.EffectiveFrom = date.Format(time.DateOnly)
+		}
+		if err := schedule.normalize(); err != nil {
+			if schedule.EffectiveFrom == "" {
+				return err
+			}
+			return fmt.Errorf("%s 起的班次安排: %v", schedule.EffectiveFrom, err)
+		}
+	}
+	sort.SliceStable(c.ShiftSchedules, func(i, j int) bool {
+		return c.ShiftSchedules[i].EffectiveFrom < c.ShiftSchedules[j].EffectiveFrom
+	})
+	for i := 1; i < len(c.ShiftSchedules); i++ {
+		if c.ShiftSchedules[i].EffectiveFrom == c.ShiftSchedules[i-1].EffectiveFrom {
+			return fmt.Errorf("生效日期 %q 重复", c.ShiftSchedules[i].EffectiveFrom)
+		}
+	}
+	return nil
+}
+
+// normalize 解析班次时间并按开始时间排列，班次必须首尾相接地覆盖一整天
+func (s *ShiftSchedule) normalize() error {
+	if len(s.Shifts) == 0 {
+		return errors.New("班次安排中没有班次")
+	}
+	names := make(map[string]bool)
+	total := 0
+	for i := range s.Shifts {
+		shift := &s.Shifts[i]
+		start, err := parseClock(shift.Start)
+		if err != nil {
+			return err
+		}
+		end, err := parseClock(shift.End)
+		if err != nil {
+			return err
+		}
+		shift.start = start % minutesPerDay
+		// 结束时间不晚于开始时间的班次跨越午夜，只有一个班次时为全天
+		shift.length = (end - shift.start + minutesPerDay) % minutesPerDay
+		if shift.length == 0 {
+			shift.length = minutesPerDay
+		}
+		shift.Name = strings.TrimSpace(shift.Name)
+		if shift.Name == "" {
+			shift.Name = shift.Start + "-" + shift.End
+		}
+		if names[shift.Name] {
+			return fmt.Errorf("班次名称 %s 重复", shift.Name)
+		}
+		names[shift.Name] = true
+		total += shift.length
+	}
+	sort.Slice(s.Shifts, func(i, j int) bool { return s.Shifts[i].start < s.Shifts[j].start })
+	for i, shift := range s.Shifts {
+		next := s.Shifts[(i+1)%len(s.Shifts)]
+		if (shift.start+shift.length)%minutesPerDay != next.start {
+			return fmt.Errorf("班次 %s 与 %s 没有首尾相接", shift.Name, next.Name)
+		}
+	}
+	if total != minutesPerDay {
+		return errors.New("班次必须首尾相接地覆盖一整天")
+	}
+	return nil
+}
+
+// shiftSlot 一条记录所在的班次：班次名称和班次开始的日期（船舶时区）
+type shiftSlot struct {
+	Name string
+	Date string
+}
+
+// scheduleOn 返回某天（YYYY-MM-DD）生效的班次安排；早于最早生效日期时使用最早的一套
+func (c *ShipConfig) scheduleOn(date string) *ShiftSchedule {
+	schedule := &c.ShiftSchedules[0]
+	for i := range c.ShiftSchedules {
+		if c.ShiftSchedules[i].EffectiveFrom <= date {
+			schedule = &c.ShiftSchedules[i]
+		}
+	}
+	return schedule
+}
+
+// shiftAt 返回船舶时区中某个时刻所在的班次。跨越午夜的班次归属开始的那一天，按那一天生效的班次安排划分
+func (c *ShipConfig) shiftAt(t time.Time) shiftSlot {
+	today := t.Format(time.DateOnly)
+	yesterday := t.AddDate(0, 0, -1).Format(time.DateOnly)
+	minute := t.Hour()*60 + t.Minute()
+
+	// 当天开始的班次
+	schedule := c.scheduleOn(today)
+	for _, shift := range schedule.Shifts {
+		if minute >= shift.start && minute < shift.start+shift.length {
+			return shiftSlot{Name: shift.Name, Date: today}
+		}
+	}
+	// 前一天开始、跨越午夜的班次
+	for _, shift := range c.scheduleOn(yesterday).Shifts {
+		if minute < shift.start+shift.length-minutesPerDay {
+			return shiftSlot{Name: shift.Name, Date: yesterday}
+		}
+	}
+	// 更换班次安排的当天，前一天的夜班与新安排衔接不上时，按新安排划分
+	for _, shift := range schedule.Shifts {
+		if minute < shift.start+shift.length-minutesPerDay {
+			return shiftSlot{Name: shift.Name, Date: yesterday}
+		}
+	}
+	last := schedule.Shifts[len(schedule.Shifts)-1]
+	return shiftSlot{Name: last.Name, Date: today}
+}
+
+// shiftNames 返回所有班次安排中的班次名称，按生效日期和开始时间排列，不重复
+func (c *ShipConfig) shiftNames() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, schedule := range c.ShiftSchedules {
+		for _, shift := range schedule.Shifts {
+			if !seen[shift.Name] {
+				seen[shift.Name] = true
+				names = append(names, shift.Name)
+			}
+		}
+	}
+	return names
+}
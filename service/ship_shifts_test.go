@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShiftAt(t *testing.T) {
+	twoShifts := []ShipShift{
+		{Name: "白班", Start: "08:00", End: "20:00"},
+		{Name: "夜班", Start: "20:00", End: "08:00"},
+	}
+	ship := &ShipConfig{ShiftSchedules: []ShiftSchedule{
+		{Shifts: defaultShipShifts()},
+		{EffectiveFrom: "2026-03-10", Shifts: twoShifts},
+		{EffectiveFrom: "2026-04-01", Shifts: defaultShipShifts()},
+	}}
+	if err := ship.normalizeShiftSchedules(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		at   string
+		want shiftSlot
+	}{
+		{name: "最早的安排", at: "2026-03-09 03:00", want: shiftSlot{Name: "0-6", Date: "2026-03-09"}},
+		{name: "班次结束时刻属于下一个班次", at: "2026-03-09 06:00", want: shiftSlot{Name: "6-12", Date: "2026-03-09"}},
+		{name: "更换安排当天午夜后按新安排的夜班归属前一天", at: "2026-03-10 03:00", want: shiftSlot{Name: "夜班", Date: "2026-03-09"}},
+		{name: "更换安排当天的白班", at: "2026-03-10 09:00", want: shiftSlot{Name: "白班", Date: "2026-03-10"}},
+		{name: "夜班开始", at: "2026-03-10 21:00", want: shiftSlot{Name: "夜班", Date: "2026-03-10"}},
+		{name: "跨越午夜的夜班归属开始的那一天", at: "2026-03-11 07:59", want: shiftSlot{Name: "夜班", Date: "2026-03-10"}},
+		{name: "换回不跨夜的安排当天按新安排划分", at: "2026-04-01 03:00", want: shiftSlot{Name: "0-6", Date: "2026-04-01"}},
+		{name: "换回前一天的夜班", at: "2026-03-31 23:00", want: shiftSlot{Name: "夜班", Date: "2026-03-31"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, err := time.ParseInLocation("2006-01-02 15:04", tt.at, time.UTC)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ship.shiftAt(at); got != tt.want {
+				t.Errorf("shiftAt(%s) = %+v, want %+v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeShiftSchedules(t *testing.T) {
+	tests := []struct {
+		name      string
+		schedules []ShiftSchedule
+		wantErr   bool
+	}{
+		{name: "未配置时使用默认班次"},
+		{name: "单个班次覆盖全天", schedules: []ShiftSchedule{{Shifts: []ShipShift{{Name: "全天", Start: "07:00", End: "07:00"}}}}},
+		{
+			name:      "班次之间有空档",
+			schedules: []ShiftSchedule{{Shifts: []ShipShift{{Start: "08:00", End: "19:00"}, {Start: "20:00", End: "08:00"}}}},
+			wantErr:   true,
+		},
+		{
+			name:      "时间格式有误",
+			schedules: []ShiftSchedule{{Shifts: []ShipShift{{Start: "8:00", End: "08:00"}}}},
+			wantErr:   true,
+		},
+		{
+			name: "生效日期重复",
+			schedules: []ShiftSchedule{
+				{EffectiveFrom: "2026-03-10", Shifts: defaultShipShifts()},
+				{EffectiveFrom: "2026-03-10", Shifts: defaultShipShifts()},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ship := &ShipConfig{ShiftSchedules: tt.schedules}
+			err := ship.normalizeShiftSchedules()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("normalizeShiftSchedules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
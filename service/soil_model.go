@@ -0,0 +1,114 @@
+package service
+
+import (
+	"dredger/model"
+	"dredger/pkg/logger"
+	"fmt"
+)
+
+// 最优分析的分组方式
+const (
+	GroupBySoil  = "soil"  // 按土质分组，每种土质分别比较班组
+	GroupByShift = "shift" // 不区分土质，所有数据归到 "default" 组比较班组
+	GroupByBoth  = "both"  // 同时返回各土质分组和不区分土质的 "default" 组
+)
+
+// allSoils 不区分土质时的分组名称
+const allSoils = "default"
+
+// defaultSoilCoordinates 绞刀 Y 坐标对应土质区域的 X 坐标，绞刀 X 坐标对应 Y 坐标，绞刀（桥架）深度对应 Z 坐标
+func defaultSoilCoordinates() SoilCoordinates {
+	return SoilCoordinates{X: "cutterY", Y: "cutterX", Z: "cutterDepth"}
+}
+
+// normalize 补全默认的坐标字段，字段必须是统一视图中的字段
+func (c *SoilCoordinates) normalize() error {
+	defaults := defaultSoilCoordinates()
+	for _, f := range []struct {
+		axis  string
+		field *string
+		def   string
+	}{
+		{"X", &c.X, defaults.X},
+		{"Y", &c.Y, defaults.Y},
+		{"Z", &c.Z, defaults.Z},
+	} {
+		if *f.field == "" {
+			*f.field = f.def
+		}
+		if _, ok := recordFields[*f.field]; !ok {
+			return fmt.Errorf("土质区域 %s 坐标对应的字段 %s 不是统一视图中的字段", f.axis, *f.field)
+		}
+	}
+	return nil
+}
+
+// soilClassifier 按船舶登记的坐标字段在土质区域中查找记录所在的土质
+type soilClassifier struct {
+	coords  SoilCoordinates
+	regions []model.SoilRegion
+}
+
+// loadSoilClassifier 加载土质区域
+func (s *Service) loadSoilClassifier(ship *ShipConfig) (*soilClassifier, error) {
+	classifier := &soilClassifier{coords: ship.SoilCoordinates}
+	if err := s.db.Find(&classifier.regions).Error; err != nil {
+		logger.Logger.Errorf("加载土质区域数据失败: %v", err)
+		return nil, err
+	}
+	return classifier, nil
+}
+
+// soilType 返回记录所在的土质，不在任何区域内时返回 "未知土质"
+func (c *soilClassifier) soilType(r *record) string {
+	x := *recordFields[c.coords.X](r)
+	y := *recordFields[c.coords.Y](r)
+	z := *recordFields[c.coords.Z](r)
+	// findSoilType 的前两个参数分别与土质区域的 Y、X 坐标比较
+	return findSoilType(y, x, z, c.regions)
+}
+
+// recordsBySoil 按分组方式对记录分组；未指定分组方式时，登记了土质模型的船舶按土质分组，其余不区分土质
+func (s *Service) recordsBySoil(ship *ShipConfig, records []*record, groupBy string) (map[string][]*record, error) {
+	groupBy, err := ship.soilGroupBy(groupBy)
+	if err != nil {
+		return nil, err
+	}
+	var classifier *soilClassifier
+	if groupBy != GroupByShift {
+		if classifier, err = s.loadSoilClassifier(ship); err != nil {
+			return nil, err
+		}
+	}
+	return splitBySoil(records, groupBy, classifier), nil
+}
+
+// soilGroupBy 校验分组方式，为空时按船舶登记的土质模型确定
+func (c *ShipConfig) soilGroupBy(groupBy string) (string, error) {
+	switch groupBy {
+	case "":
+		if c.SoilModel == SoilModelRegions {
+			return GroupBySoil, nil
+		}
+		return GroupByShift, nil
+	case GroupBySoil, GroupByShift, GroupByBoth:
+		return groupBy, nil
+	}
+	return "", fmt.Errorf("不支持的分组方式 %q，应为 %s、%s 或 %s", groupBy, GroupBySoil, GroupByShift, GroupByBoth)
+}
+
+// splitBySoil 按已校验的分组方式对记录分组，不区分土质时 classifier 可以为 nil
+func splitBySoil(records []*record, groupBy string, classifier *soilClassifier) map[string][]*record {
+	groups := make(map[string][]*record)
+	if groupBy != GroupBySoil {
+		groups[allSoils] = records
+	}
+	if groupBy == GroupByShift {
+		return groups
+	}
+	for _, r := range records {
+		soilType := classifier.soilType(r)
+		groups[soilType] = append(groups[soilType], r)
+	}
+	return groups
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"dredger/model"
+)
+
+func TestSplitBySoil(t *testing.T) {
+	classifier := &soilClassifier{
+		coords: defaultSoilCoordinates(),
+		regions: []model.SoilRegion{
+			{XMin: 0, XMax: 10, YMin: 0, YMax: 10, ZMin: -20, ZMax: 0, SoilType: "黏土"},
+			{XMin: 10, XMax: 20, YMin: 0, YMax: 10, ZMin: -20, ZMax: 0, SoilType: "砂"},
+		},
+	}
+	// 土质区域的 X 坐标对应绞刀 Y 坐标
+	clay := &record{CutterY: 5, CutterX: 5, CutterDepth: -5}
+	sand := &record{CutterY: 15, CutterX: 5, CutterDepth: -5}
+	outside := &record{CutterY: 5, CutterX: 5, CutterDepth: -30}
+	records := []*record{clay, sand, outside}
+
+	tests := []struct {
+		name      string
+		soilModel string
+		groupBy   string
+		want      map[string][]*record
+		wantErr   bool
+	}{
+		{
+			name:      "未登记土质模型时不区分土质",
+			soilModel: SoilModelNone,
+			want:      map[string][]*record{allSoils: records},
+		},
+		{
+			name:      "登记了土质模型时按土质分组",
+			soilModel: SoilModelRegions,
+			want:      map[string][]*record{"黏土": {clay}, "砂": {sand}, "未知土质": {outside}},
+		},
+		{
+			name:      "明确指定不区分土质",
+			soilModel: SoilModelRegions,
+			groupBy:   GroupByShift,
+			want:      map[string][]*record{allSoils: records},
+		},
+		{
+			name:      "同时返回两种分组",
+			soilModel: SoilModelNone,
+			groupBy:   GroupByBoth,
+			want:      map[string][]*record{allSoils: records, "黏土": {clay}, "砂": {sand}, "未知土质": {outside}},
+		},
+		{
+			name:    "不支持的分组方式",
+			groupBy: "crew",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ship := &ShipConfig{SoilModel: tt.soilModel}
+			groupBy, err := ship.soilGroupBy(tt.groupBy)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("soilGroupBy() 应返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := splitBySoil(records, groupBy, classifier); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitBySoil() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
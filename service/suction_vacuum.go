@@ -1,73 +1,64 @@
 package service
 
 import (
-	"dredger/model"
 	"math"
-	"strings"
 )
 
 type ShipHydraulicsConfig struct {
-	PatmPa                   float64 // 大气压，Pa（默认 101325）
-	G                        float64 // 重力加速度，m/s^2（默认 9.80665）
-	PipeInnerDiameterM       float64 // 吸入管内径 m（若数据表已有，则此项可为空）
-	SuctionPipeLengthM       float64 // 直管长度 m（来自 Word/设备台账）
-	LocalEqLengthM           float64 // 局部件当量长度 m（来自 Word 表格折算）
-	FrictionFactorClearWater float64 // 清水沿程阻力系数 f_cw（或直接填泥浆用 f）
-	UseDensityRatio          bool    // 是否用 (rho_m/rho_w) 放大 f_cw 得到泥浆 f
-	PumpAboveBottomM         float64 // 泵中心线高于船底的高度 m（来自布置）
-	DefaultHsPumpM           float64 // 新增：当几何量缺失时的保守回退（单位 m）
-	FlowRateUnit             string  // "m3/h" 或 "m3/s"
-	DensityUnit              string  // "kg/m3" / "t/m3" / "g/cm3"
-	VacuumOutUnit            string  // "kPa"（默认）
+	PatmPa                   float64 `json:"patmPa"`                   // 大气压，Pa（默认 101325）
+	G                        float64 `json:"g"`                        // 重力加速度，m/s^2（默认 9.80665）
+	PipeInnerDiameterM       float64 `json:"pipeInnerDiameterM"`       // 吸入管内径 m（若数据表已有，则此项可为空）
+	SuctionPipeLengthM       float64 `json:"suctionPipeLengthM"`       // 直管长度 m（来自 Word/设备台账）
+	LocalEqLengthM           float64 `json:"localEqLengthM"`           // 局部件当量长度 m（来自 Word 表格折算）
+	FrictionFactorClearWater float64 `json:"frictionFactorClearWater"` // 清水沿程阻力系数 f_cw（或直接填泥浆用 f）
+	UseDensityRatio          bool    `json:"useDensityRatio"`          // 是否用 (rho_m/rho_w) 放大 f_cw 得到泥浆 f
+	PumpAboveBottomM         float64 `json:"pumpAboveBottomM"`         // 泵中心线高于船底的高度 m（来自布置）
+	DefaultHsPumpM           float64 `json:"defaultHsPumpM"`           // 新增：当几何量缺失时的保守回退（单位 m）
+	FlowRateUnit             string  `json:"flowRateUnit"`             // "m3/h" 或 "m3/s"
+	DensityUnit              string  `json:"densityUnit"`              // "kg/m3" / "t/m3" / "g/cm3"
+	VacuumOutUnit            string  `json:"vacuumOutUnit"`            // "kPa"（默认）
+	MaxVacuumKPa             float64 `json:"maxVacuumKPa"`             // 允许的最大吸入真空度 kPa，用于计算真空余量；0 表示未登记
 }
 
-func GetCfg(ship string) ShipHydraulicsConfig {
-	if strings.Contains(ship, "华安龙") {
-		return ShipHydraulicsConfig{
-			PatmPa:                   101325,
-			G:                        9.80665,
-			PipeInnerDiameterM:       0.0,
-			SuctionPipeLengthM:       77.36,
-			LocalEqLengthM:           0.0,
-			FrictionFactorClearWater: 0.0130,
-			UseDensityRatio:          true,
-			PumpAboveBottomM:         2.5,
-			DefaultHsPumpM:           2.5,
-			FlowRateUnit:             "m3/h",
-			DensityUnit:              "",
-			VacuumOutUnit:            "kPa",
-		}
-	} else {
-		return ShipHydraulicsConfig{
-			PatmPa: 101325,
-			G:      9.80665,
-			// D：Excel “泥管直径” = 0.70m，本配置置0表示优先用记录里的值
-			PipeInnerDiameterM: 0.0,
+// defaultHydraulicsConfig 登记船舶时未填写水力参数时使用的默认值
+func defaultHydraulicsConfig() ShipHydraulicsConfig {
+	return ShipHydraulicsConfig{
+		PatmPa: 101325,
+		G:      9.80665,
+		// D：Excel “泥管直径” = 0.70m，本配置置0表示优先用记录里的值
+		PipeInnerDiameterM: 0.0,
 
-			// L：用表8.3.3-2（2.4中值）× Excel 几何直管均值(≈32.23m) → 77.36m
-			SuctionPipeLengthM: 77.36,
+		// L：用表8.3.3-2（2.4中值）× Excel 几何直管均值(≈32.23m) → 77.36m
+		SuctionPipeLengthM: 77.36,
 
-			// 采用折算比方案时，局部件已包含在折算里，这里设 0
-			LocalEqLengthM: 0.0,
+		// 采用折算比方案时，局部件已包含在折算里，这里设 0
+		LocalEqLengthM: 0.0,
 
-			// 表2.1（D=0.70m）清水沿程阻力系数
-			FrictionFactorClearWater: 0.0130,
+		// 表2.1（D=0.70m）清水沿程阻力系数
+		FrictionFactorClearWater: 0.0130,
 
-			// 按Word：泥浆 f = 清水 f × (ρm/ρw)
-			UseDensityRatio: true,
+		// 按Word：泥浆 f = 清水 f × (ρm/ρw)
+		UseDensityRatio: true,
 
-			// 布置图尺寸（暂以 2.5m 先跑通；拿到真值就替换）
-			PumpAboveBottomM: 2.5,
+		// 布置图尺寸（暂以 2.5m 先跑通；拿到真值就替换）
+		PumpAboveBottomM: 2.5,
 
-			// 兜底：若几何缺失时使用（可以与上面相同）
-			DefaultHsPumpM: 2.5,
+		// 兜底：若几何缺失时使用（可以与上面相同）
+		DefaultHsPumpM: 2.5,
 
-			// 与Excel一致
-			FlowRateUnit:  "m3/h",
-			DensityUnit:   "", // 让代码按 0~5 识别成相对密度×1000
-			VacuumOutUnit: "kPa",
-		}
+		// 与Excel一致
+		FlowRateUnit:  "m3/h",
+		DensityUnit:   "", // 让代码按 0~5 识别成相对密度×1000
+		VacuumOutUnit: "kPa",
+	}
+}
+
+// GetCfg 返回船舶登记的吸入管路水力参数，未登记的船舶使用默认值
+func GetCfg(ship string) ShipHydraulicsConfig {
+	if c, err := lookupShip(ship); err == nil {
+		return c.Hydraulics
 	}
+	return defaultHydraulicsConfig()
 }
 
 func densityToKgM3(v float64, unit string) float64 {
@@ -92,7 +83,7 @@ func flowRateToM3s(v float64, unit string) float64 {
 	return v // 认为已是 m3/s
 }
 
-func pipeD(r *model.DredgerDatum, cfg ShipHydraulicsConfig) float64 {
+func pipeD(r *record, cfg ShipHydraulicsConfig) float64 {
 	if cfg.PipeInnerDiameterM > 0 {
 		return cfg.PipeInnerDiameterM
 	}
@@ -103,7 +94,7 @@ func pipeD(r *model.DredgerDatum, cfg ShipHydraulicsConfig) float64 {
 	return r.MudPipeDiameter
 }
 
-func flowVelocityVs(r *model.DredgerDatum, cfg ShipHydraulicsConfig, D float64) float64 {
+func flowVelocityVs(r *record, cfg ShipHydraulicsConfig, D float64) float64 {
 	if r.FlowVelocity > 0 {
 		return r.FlowVelocity
 	}
@@ -118,7 +109,7 @@ func flowVelocityVs(r *model.DredgerDatum, cfg ShipHydraulicsConfig, D float64)
 	return Q / A
 }
 
-func suctionDepthHsPipe(r *model.DredgerDatum) float64 {
+func suctionDepthHsPipe(r *record) float64 {
 	// 约定：CutterDepth 为相对水面的深度（向下为正）
 	// 若你的定义不同，请在这里按 BridgeWaterDepth 做一次换算
 	if r.CutterDepth > 0 {
@@ -127,7 +118,7 @@ func suctionDepthHsPipe(r *model.DredgerDatum) float64 {
 	return math.NaN()
 }
 
-func pumpDepthHsPump(r *model.DredgerDatum, cfg ShipHydraulicsConfig) float64 {
+func pumpDepthHsPump(r *record, cfg ShipHydraulicsConfig) float64 {
 	ear := r.EarDraft
 	if ear == 0 {
 		// 取左右平均
@@ -153,7 +144,7 @@ func frictionFactorMud(cfg ShipHydraulicsConfig, rhoW, rhoM float64) float64 {
 }
 
 // 返回：按 Word 公式得到的“真空度”，默认 kPa
-func calcVacuumKPa(r *model.DredgerDatum, cfg ShipHydraulicsConfig) float64 {
+func calcVacuumKPa(r *record, cfg ShipHydraulicsConfig) float64 {
 	g := cfg.G
 	if g == 0 {
 		g = 9.80665
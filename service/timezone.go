@@ -0,0 +1,104 @@
+package service
+
+import (
+	"dredger/pkg/conf"
+	"dredger/pkg/logger"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// 已加载的时区，键为配置中的时区名
+var locations sync.Map
+
+// ShipLocation 返回船舶所在的时区：优先取船舶登记的时区，其次 timezone.ships 中为该船配置的时区和 timezone.default，
+// 都未配置时使用服务器本地时区。导入时解析时间、按班次划分小时以及显示时间都使用该时区
+func ShipLocation(shipName string) *time.Location {
+	var name string
+	if ship, err := lookupShip(shipName); err == nil {
+		name = ship.Timezone
+	}
+	if name == "" && conf.Conf != nil {
+		// viper 会将 map 的键转为小写
+		name = conf.Conf.GetStringMapString("timezone.ships")[strings.ToLower(shipName)]
+	}
+	if name == "" && conf.Conf != nil {
+		name = conf.Conf.GetString("timezone.default")
+	}
+	if name == "" {
+		return time.Local
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		logger.Logger.Errorf("加载船舶 %s 的时区 %s 失败，使用服务器本地时区: %v", shipName, name, err)
+		return time.Local
+	}
+	locations.Store(name, loc)
+	return loc
+}
+
+// 不带时区的时间格式，按船舶时区解析。Go 解析时会自动接受秒后面的小数部分
+var recordTimeLayouts = []string{
+	time.DateTime,
+	"2006/01/02 15:04:05",
+	"2006-1-2 15:04:05",
+	"2006/1/2 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-1-2 15:04",
+	"2006/1/2 15:04",
+}
+
+// Excel 序列日期的合理范围（1900 年至 2173 年），超出范围的数字按 Unix 时间戳处理
+const (
+	excelSerialMin = 1
+	excelSerialMax = 100000
+)
+
+// parseRecordTime 解析时间列，支持：
+//   - yyyy-MM-dd HH:mm:ss、yyyy/MM/dd HH:mm:ss 及省略前导零或秒的写法，按 loc 解析
+//   - ISO-8601，带时区偏移时按偏移解析，不带时按 loc 解析
+//   - Excel 序列日期（如 45293.5），按 loc 解析
+//   - Unix 时间戳，10 位为秒，13 位为毫秒
+func parseRecordTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, errors.New("时间为空")
+	}
+
+	for _, layout := range recordTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(num) || math.IsInf(num, 0) {
+		return time.Time{}, errors.New("无法识别的时间格式")
+	}
+	switch {
+	case num >= 1e11:
+		return time.UnixMilli(int64(num)), nil
+	case num >= 1e9:
+		return time.UnixMilli(int64(math.Round(num * 1000))), nil
+	case num >= excelSerialMin && num < excelSerialMax:
+		// ExcelDateToTime 返回的是 UTC 下的“墙上时间”，按船舶时区重新解释
+		t, err := excelize.ExcelDateToTime(num, false)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = t.Round(time.Millisecond)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), nil
+	}
+	return time.Time{}, errors.New("无法识别的时间格式")
+}
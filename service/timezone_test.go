@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecordTime(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	// 2026-03-10 08:00:00 +08:00，Unix 时间戳 1773100800
+	base := time.Date(2026, 3, 10, 8, 0, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "标准格式按船舶时区", value: "2026-03-10 08:00:00", want: base},
+		{name: "斜杠、省略前导零和秒", value: " 2026/3/10 8:00 ", want: base},
+		{name: "秒的小数部分", value: "2026-03-10 08:00:00.250", want: base.Add(250 * time.Millisecond)},
+		{name: "ISO-8601 不带时区", value: "2026-03-10T08:00:00", want: base},
+		{name: "ISO-8601 UTC", value: "2026-03-10T00:00:00Z", want: base},
+		{name: "ISO-8601 带偏移", value: "2026-03-10T09:00:00+09:00", want: base},
+		{name: "Unix 秒", value: "1773100800", want: base},
+		{name: "Unix 秒带小数", value: "1773100800.5", want: base.Add(500 * time.Millisecond)},
+		{name: "Unix 毫秒", value: "1773100800123", want: base.Add(123 * time.Millisecond)},
+		{name: "Excel 序列日期按船舶时区", value: "46091.3333333333", want: base},
+		{name: "Excel 序列日期整天", value: "46091", want: base.Add(-8 * time.Hour)},
+		{name: "空值", value: "  ", wantErr: true},
+		{name: "无法识别的文本", value: "3月10日", wantErr: true},
+		{name: "介于序列日期和时间戳之间的数字", value: "12345678", wantErr: true},
+		{name: "NaN", value: "NaN", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseRecordTime(tt.value, loc)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseRecordTime(%q) = %v, 应返回错误", tt.value, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseRecordTime(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
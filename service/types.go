@@ -8,17 +8,215 @@ const (
 )
 
 type ImportDataResult struct {
-	ImportedRows int `json:"importedRows"`
+	ImportedRows int                  `json:"importedRows"`
+	ParsedRows   int                  `json:"parsedRows"`
+	SkippedRows  int                  `json:"skippedRows"`
+	Mapping      *ColumnMappingReport `json:"mapping,omitempty"`
+	Rejections   map[string]int       `json:"rejections,omitempty"`   // 各拒绝原因对应的行数
+	RejectedRows []RejectedRow        `json:"rejectedRows,omitempty"` // 前 N 条被拒绝的行
+	RejectFile   string               `json:"rejectFile,omitempty"`   // 被拒绝行的 XLSX 文件名
+	RejectPath   string               `json:"rejectPath,omitempty"`   // 拒绝文件的完整路径，通过 /v1/files/serve?path=... 下载
+	Preview      *ImportPreview       `json:"preview,omitempty"`      // 仅 dryRun 时返回
+	Mode         string               `json:"mode"`
+	Buckets      ImportBuckets        `json:"buckets"`
+	BatchID      int64                `json:"batchId,omitempty"` // 本次导入的批次，dryRun 时为空
+	FileHash     string               `json:"fileHash"`
+	Format       string               `json:"format"` // 识别出的文件格式：xlsx / csv / parquet
+}
+
+// 导入模式：与已有数据时间戳相同（或文件内时间重复）时的处理方式
+const (
+	ImportModeSkip      = "skip"      // 保留已有数据，跳过冲突行
+	ImportModeOverwrite = "overwrite" // 用文件中的数据覆盖已有数据
+	ImportModeFail      = "fail"      // 出现冲突即中止导入并回滚
+)
+
+// ImportBuckets 有效行按导入模式的归类统计，dryRun 时为预计结果
+type ImportBuckets struct {
+	Inserted    int `json:"inserted"`    // 新增的行
+	Overwritten int `json:"overwritten"` // 覆盖已有数据的行
+	Unchanged   int `json:"unchanged"`   // 已有数据保持不变、被跳过的行
+	Conflicts   int `json:"conflicts"`   // fail 模式下发生冲突的行
+}
+
+// ImportPreview 预览（dryRun）模式的结果，数据库不会有任何改动；将新增、覆盖的行数见 ImportDataResult.Buckets
+type ImportPreview struct {
+	StartTime int64 `json:"startTime"` // 文件中最早的记录时间（毫秒）
+	EndTime   int64 `json:"endTime"`   // 文件中最晚的记录时间（毫秒）
+	Samples   []any `json:"samples"`   // 前几条解析后的记录
+	// ImportedBatch 相同内容的文件已导入且未回滚时为该批次，正式导入会被拒绝
+	ImportedBatch *ImportBatch `json:"importedBatch,omitempty"`
+}
+
+// 导入时行被拒绝的原因
+const (
+	RejectShortRow    = "shortRow"    // 列数不足
+	RejectBadTime     = "badTime"     // 时间格式错误
+	RejectBadFloat    = "badFloat"    // 数值格式错误
+	RejectBadInt      = "badInt"      // 整数格式错误
+	RejectUnsupported = "unsupported" // 字段类型不支持
+)
+
+// RejectedRow 一条被拒绝的行
+type RejectedRow struct {
+	Sheet   string `json:"sheet,omitempty"` // XLSX 中所在的工作表
+	Row     int    `json:"row"`             // 所在工作表（其它格式为文件）中的行号，表头为第 1 行
+	Reason  string `json:"reason"`
+	Column  string `json:"column,omitempty"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message"`
+}
+
+// ColumnMappingReport 表头与数据库字段的匹配结果
+type ColumnMappingReport struct {
+	Mapped          []MappedColumn `json:"mapped"`
+	UnknownHeaders  []string       `json:"unknownHeaders"`  // 文件中无法识别的列
+	UnmappedColumns []string       `json:"unmappedColumns"` // 文件中未提供的数据库字段
+}
+
+type MappedColumn struct {
+	Header            string `json:"header"`
+	ColumnName        string `json:"columnName"`
+	ColumnChineseName string `json:"columnChineseName"`
+}
+
+// ImportOptions 描述一次数据导入
+type ImportOptions struct {
+	FileName  string
+	ShipName  string
+	Cover     bool   // 兼容旧参数，Mode 为空时 true 等同于 overwrite
+	Mode      string // ImportMode* 常量
+	DryRun    bool   // 只解析和统计，不写库
+	Uploader  string // 上传人
+	JobID     int64  // 所属导入任务，同步导入时为 0
+	StartDate int64  // 文件名中的起始日期（毫秒）
+	EndDate   int64  // 文件名中的结束日期（毫秒）
+}
+
+// ExportOptions 描述一次数据导出
+type ExportOptions struct {
+	ShipName  string
+	StartTime int64    // 起始时间（毫秒，含）
+	EndTime   int64    // 结束时间（毫秒，含）
+	Format    string   // FileFormat* 常量
+	Columns   []string // 导出的数据库列名，为空时导出全部列
+	Encoding  string   // CSV 编码，CSVEncoding* 常量
+}
+
+func (o *ImportOptions) normalize() {
+	if o.Mode != "" {
+		return
+	}
+	o.Mode = ImportModeSkip
+	if o.Cover {
+		o.Mode = ImportModeOverwrite
+	}
+}
+
+// 导入任务状态
+const (
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobSucceeded = "succeeded"
+	ImportJobFailed    = "failed"
+	ImportJobCanceled  = "canceled"
+)
+
+// 导入任务所处阶段
+const (
+	ImportPhaseQueued     = "queued"
+	ImportPhaseParsing    = "parsing"
+	ImportPhaseInserting  = "inserting"
+	ImportPhaseCommitting = "committing"
+	ImportPhaseDone       = "done"
+)
+
+type ImportProgress struct {
+	Phase        string `json:"phase"`
+	RowsParsed   int    `json:"rowsParsed"`
+	RowsInserted int    `json:"rowsInserted"`
+	RowsSkipped  int    `json:"rowsSkipped"`
+}
+
+type ImportJob struct {
+	ID         int64  `json:"id"`
+	FileName   string `json:"fileName"`
+	ShipName   string `json:"shipName"`
+	Cover      bool   `json:"cover"`
+	Mode       string `json:"mode"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	CreatedAt  int64  `json:"createdAt"`
+	StartedAt  int64  `json:"startedAt,omitempty"`
+	FinishedAt int64  `json:"finishedAt,omitempty"`
+	ImportProgress
+	Result *ImportDataResult `json:"result,omitempty"`
+}
+
+// ImportBatch 一次成功导入的来源文件及其写入的数据量
+type ImportBatch struct {
+	ID              int64  `json:"id"`
+	JobID           int64  `json:"jobId,omitempty"`
+	FileName        string `json:"fileName"`
+	FileHash        string `json:"fileHash"`
+	ShipName        string `json:"shipName"`
+	Uploader        string `json:"uploader"`
+	Mode            string `json:"mode"`
+	Status          string `json:"status"`
+	CreatedAt       int64  `json:"createdAt"`
+	StartTime       int64  `json:"startTime"` // 最早记录时间（毫秒）
+	EndTime         int64  `json:"endTime"`   // 最晚记录时间（毫秒）
+	RowsParsed      int64  `json:"rowsParsed"`
+	RowsInserted    int64  `json:"rowsInserted"`
+	RowsOverwritten int64  `json:"rowsOverwritten"`
+	RowsUnchanged   int64  `json:"rowsUnchanged"`
+	RowsSkipped     int64  `json:"rowsSkipped"`
+	RowsDeleted     int64  `json:"rowsDeleted,omitempty"` // 回滚时删除的行数
+	CanRollback     bool   `json:"canRollback"`           // 未回滚且没有覆盖已有的行；覆盖过的行无法恢复，不能回滚
+	RolledBackAt    int64  `json:"rolledBackAt,omitempty"`
 }
 
 type ShiftStat struct {
-	ShiftName       string    `json:"shiftName"`
-	BeginTime       time.Time `json:"beginTime"`
-	EndTime         time.Time `json:"endTime"`
-	WorkDuration    float64   `json:"workDuration"`
-	TotalProduction float64   `json:"totalProduction"`
-	TotalEnergy     float64   `json:"totalEnergy"`
-	SoilTypes       []string  `json:"soilTypes"`
+	ShiftName           string    `json:"shiftName"`
+	Crew                string    `json:"crew,omitempty"`   // 按班组统计时的班组
+	Period              string    `json:"period,omitempty"` // 按天或按周统计时的周期：日期或该周周一的日期
+	BeginTime           time.Time `json:"beginTime"`
+	EndTime             time.Time `json:"endTime"`
+	WorkDuration        float64   `json:"workDuration"`
+	TotalProduction     float64   `json:"totalProduction"`
+	TotalEnergy         float64   `json:"totalEnergy"`         // 单位产量能耗，energyUnit 为 kWh 时单位为 kWh/m³
+	EnergyKWh           *float64  `json:"energyKWh"`           // 能耗（kWh），energyUnit 不是 kWh 时为空
+	EnergyUnit          string    `json:"energyUnit"`          // 能耗的单位：kWh，或 relative（旧版本经验公式的相对值）
+	EstimatedProduction float64   `json:"estimatedProduction"` // 按流量 × 浓度积分估算的产量，与 TotalProduction 相互校核
+	TotalFuel           float64   `json:"totalFuel"`           // 油耗（L）
+	SoilTypes           []string  `json:"soilTypes"`
+	GapCount            int       `json:"gapCount"`    // 未计入施工时长的数据中断次数
+	GapDuration         float64   `json:"gapDuration"` // 数据中断的总时长（分钟）
+}
+
+// ShiftStatSeries 按天或按周的班组统计
+type ShiftStatSeries struct {
+	Granularity string              `json:"granularity"`
+	Periods     []string            `json:"periods"`   // 有施工数据的周期，升序
+	Items       []*ShiftStat        `json:"items"`     // 每个周期每个班组的统计，按周期和班组排列
+	Summaries   []*ShiftStatSummary `json:"summaries"` // 每个班组各周期的合计与平均值
+	Total       *ShiftStatSummary   `json:"total"`     // 全部班组的合计与平均值
+}
+
+// ShiftStatSummary 多个周期的合计与每周期平均值
+type ShiftStatSummary struct {
+	ShiftName           string   `json:"shiftName,omitempty"`
+	Crew                string   `json:"crew,omitempty"`
+	Periods             int      `json:"periods"`         // 有施工的周期数
+	WorkDuration        float64  `json:"workDuration"`    // 合计施工时长（分钟）
+	TotalProduction     float64  `json:"totalProduction"` // 合计产量
+	TotalEnergy         float64  `json:"totalEnergy"`     // 单位产量能耗，与 ShiftStat.TotalEnergy 含义相同
+	EnergyKWh           *float64 `json:"energyKWh"`       // 合计能耗（kWh），energyUnit 不是 kWh 时为空
+	EnergyUnit          string   `json:"energyUnit"`
+	EstimatedProduction float64  `json:"estimatedProduction"` // 合计估算产量
+	TotalFuel           float64  `json:"totalFuel"`           // 合计油耗（L）
+	AvgWorkDuration     float64  `json:"avgWorkDuration"`     // 每个周期的平均施工时长（分钟）
+	AvgProduction       float64  `json:"avgProduction"`       // 每个周期的平均产量
 }
 
 type ParameterStat struct {
@@ -39,8 +237,41 @@ type (
 		TotalEnergy        float64          `json:"-"`
 		TotalProduction    float64          `json:"-"`
 	}
+	// ParetoOptions 多目标最优班组分析的选项
+	ParetoOptions struct {
+		Objectives    []string           // 参与比较的目标，为空时比较产量和单位能耗
+		Weights       map[string]float64 // 各目标在加权得分中的权重，未给出的目标权重为 1
+		MinProduction float64            // 产量下限（m³），低于下限的班组不参与比较
+		MaxVacuumKPa  float64            // 允许的最大吸入真空度（kPa），为 0 时使用船舶登记的值
+	}
+	// ParetoShiftResponse 多目标模式下各土质的 Pareto 最优班组
+	ParetoShiftResponse struct {
+		Objectives   []string                `json:"objectives"`
+		Weights      map[string]float64      `json:"weights"`
+		EnergyUnit   string                  `json:"energyUnit"` // 单位产量能耗的单位，同 ShiftStat.EnergyUnit
+		ParetoBySoil map[string]*ParetoFront `json:"paretoBySoil"`
+	}
+	// ParetoFront 某种土质下不被其它班组支配的班组，按加权得分从高到低排列
+	ParetoFront struct {
+		Candidates  int            `json:"candidates"` // 参与比较的班组数
+		Excluded    int            `json:"excluded"`   // 产量未达到下限而排除的班组数
+		Front       []*ParetoShift `json:"front"`
+		Recommended *ParetoShift   `json:"recommended"` // 得分最高的班组，没有候选班组时为空
+	}
+	ParetoShift struct {
+		ShiftName    string             `json:"shiftName"`
+		Crew         string             `json:"crew,omitempty"`
+		Production   float64            `json:"production"`             // 产量（m³）
+		UnitEnergy   float64            `json:"unitEnergy"`             // 单位产量能耗（kWh/m³）
+		VacuumMargin *float64           `json:"vacuumMargin,omitempty"` // 真空余量（kPa），未计算时为空
+		Normalized   map[string]float64 `json:"normalized"`             // 各目标归一化到 [0, 1] 的值，越大越好
+		Score        float64            `json:"score"`                  // 加权得分，[0, 1]
+		OptimalTime  int64              `json:"optimalTime"`
+		Parameters   ParameterStats     `json:"parameters"`
+	}
 	ShiftWorkParams struct {
 		ShiftName   string         `json:"shiftName"`
+		Crew        string         `json:"crew,omitempty"`
 		OptimalTime int64          `json:"optimalTime"`
 		Parameters  ParameterStats `json:"parameters"`
 	}
@@ -67,6 +298,54 @@ type (
 	}
 )
 
+// RecommendOptions 施工参数推荐的选项
+type RecommendOptions struct {
+	Bins       int     // 每个参数划分的区间数，默认 10
+	MinSamples int     // 区间参与推荐所需的最少样本数，默认 30
+	Tolerance  float64 // 与最优区间相差不超过该比例的相邻区间一并推荐，默认 0.1
+	GroupBy    string  // 按土质（soil）、不区分土质（shift）或两者（both）分组，为空时按船舶登记的土质模型
+}
+
+type (
+	// ParameterRecommendationResponse 各土质的施工参数推荐
+	ParameterRecommendationResponse struct {
+		Bins       int                            `json:"bins"`
+		MinSamples int                            `json:"minSamples"`
+		Tolerance  float64                        `json:"tolerance"`
+		EnergyUnit string                         `json:"energyUnit"` // 单位产量能耗的单位，同 ShiftStat.EnergyUnit
+		BySoil     map[string]*SoilRecommendation `json:"bySoil"`
+	}
+	SoilRecommendation struct {
+		SampleCount  int                        `json:"sampleCount"`  // 参与分析的施工采样间隔数
+		WorkDuration float64                    `json:"workDuration"` // 参与分析的施工时长（分钟）
+		Parameters   []*ParameterRecommendation `json:"parameters"`
+	}
+	// ParameterRecommendation 一个施工参数的分区间统计和推荐范围，推荐范围在样本不足时为空
+	ParameterRecommendation struct {
+		Name          string            `json:"name"` // 与 ParameterStats 的字段名相同
+		Label         string            `json:"label"`
+		Bins          []*ParameterBin   `json:"bins"`
+		Outliers      int               `json:"outliers"`      // 低于 5% 或高于 95% 分位数、未参与分区的样本数
+		MaxProduction *RecommendedRange `json:"maxProduction"` // 产量率最高的范围
+		MinUnitEnergy *RecommendedRange `json:"minUnitEnergy"` // 单位产量能耗最低的范围
+	}
+	ParameterBin struct {
+		Min            float64 `json:"min"`
+		Max            float64 `json:"max"`
+		SampleCount    int     `json:"sampleCount"`
+		ProductionRate float64 `json:"productionRate"` // 按时间加权的平均产量率（m³/h）
+		UnitEnergy     float64 `json:"unitEnergy"`     // 单位产量能耗（kWh/m³）
+	}
+	RecommendedRange struct {
+		Min            float64 `json:"min"`
+		Max            float64 `json:"max"`
+		SampleCount    int     `json:"sampleCount"`
+		ProductionRate float64 `json:"productionRate"`
+		UnitEnergy     float64 `json:"unitEnergy"`
+		Confidence     float64 `json:"confidence"` // 范围内外样本均值差异的单侧置信度，[0, 1]
+	}
+)
+
 type ColumnInfo struct {
 	ColumnName        string `json:"columnName"`
 	ColumnChineseName string `json:"columnChineseName"`
@@ -76,11 +355,14 @@ type ColumnInfo struct {
 type (
 	ShiftPie struct {
 		ShiftName string   `json:"shiftName"`
+		Crew      string   `json:"crew,omitempty"`
 		WorkData  *PieData `json:"workData"`
 	}
 	PieData struct {
 		TotalProduction float64 `json:"totalProduction"`
 		TotalEnergy     float64 `json:"totalEnergy"`
+		EnergyUnit      string  `json:"energyUnit"` // totalEnergy 的单位，同 ShiftStat.EnergyUnit
+		TotalFuel       float64 `json:"totalFuel"`
 		WorkDuration    float64 `json:"workDuration"`
 	}
 )
@@ -98,6 +380,174 @@ type GlobalTimeRange struct {
 	EndDateStr   string `json:"endDate"`
 }
 
+// DataCoverage 某船某天的数据覆盖情况
+type DataCoverage struct {
+	Date        int64  `json:"date"`
+	DateStr     string `json:"dateStr"`
+	RecordCount int64  `json:"recordCount"`
+	FirstTime   int64  `json:"firstTime"`
+	LastTime    int64  `json:"lastTime"`
+}
+
+// DataCoverageRebuild 重建 data_date 的结果
+type DataCoverageRebuild struct {
+	Ships   int   `json:"ships"`
+	Days    int   `json:"days"`
+	Records int64 `json:"records"`
+}
+
+// ShipConfig 船舶登记信息，决定施工数据存放的表、时区、班次划分、土质模型和实时传感器点位
+type ShipConfig struct {
+	Name            string               `json:"name"`
+	DataSchema      string               `json:"dataSchema"` // 施工数据结构：hl 存 dredger_data_hl，ml 存 dredger_data
+	Timezone        string               `json:"timezone"`   // 为空时使用 timezone 配置
+	Hydraulics      ShipHydraulicsConfig `json:"hydraulics"`
+	Energy          ShipEnergyModel      `json:"energy"` // 未配置时按数据结构使用默认的能耗模型
+	Duration        ShipDurationRule     `json:"duration"`
+	States          ShipStateRule        `json:"states"`
+	ShiftSchedules  []ShiftSchedule      `json:"shiftSchedules"`  // 按生效日期排列，每套使用到下一套生效
+	SoilModel       string               `json:"soilModel"`       // 最优分析默认的分组：none 不区分土质，regions 按土质区域分组
+	SoilCoordinates SoilCoordinates      `json:"soilCoordinates"` // 土质区域坐标对应的字段，未配置时使用绞刀坐标和绞刀深度
+	SensorPoints    *ShipSensorPoints    `json:"sensorPoints"`    // 未接入实时传感器时为空
+	CrewRotations   []CrewRotation       `json:"crewRotations"`   // 按开始日期排列，每套使用到下一套开始
+	// Fields 覆盖数据结构默认的统一视图字段映射，键为 outputRate、cutterDepth、dischargePressure 等
+	Fields    map[string]RecordField `json:"fields"`
+	CreatedAt time.Time              `json:"createdAt"`
+	UpdatedAt time.Time              `json:"updatedAt"`
+
+	view *recordView // 按数据结构和 Fields 建立的统一视图
+}
+
+// SoilCoordinates 土质区域（soil_regions）的 X、Y、Z 坐标分别对应的统一视图字段，如 cutterX、cutterY、cutterDepth
+type SoilCoordinates struct {
+	X string `json:"x"`
+	Y string `json:"y"`
+	Z string `json:"z"`
+}
+
+// ShipDurationRule 施工时长的计算规则：累加相邻记录的采样间隔，数据中断和未达到施工条件的间隔不计入
+type ShipDurationRule struct {
+	MaxGapSeconds float64 `json:"maxGapSeconds"` // 相邻记录间隔超过该值视为数据中断，为 0 时使用默认值
+	MinOutputRate float64 `json:"minOutputRate"` // 大于 0 时，间隔起点的产量率低于该值不计入
+	MinPumpSpeed  float64 `json:"minPumpSpeed"`  // 大于 0 时，间隔起点的水下泵转速低于该值不计入
+}
+
+// ShipEnergyModel 能耗模型，决定功率（kW）和油耗率（L/h）的计算方式，使各船的能耗和单位产量能耗含义一致
+type ShipEnergyModel struct {
+	Type string `json:"type"` // hydraulic、electrical 或 diesel
+
+	// hydraulic：各级泵的功率 = Coefficient × 流量 × (出口压力 - 进口压力)
+	FlowColumn string        `json:"flowColumn,omitempty"` // 为空时使用 flow_rate
+	Stages     []EnergyStage `json:"stages,omitempty"`     // 各级泵
+	// Coefficient 为 0 时使用 0.8，与旧版本的经验公式一致，此时能耗是没有单位的相对值。按物理单位计算时须明确配置，
+	// 如流量 m³/h、压力 MPa、泵效率 η 时为 1000 / 3600 / η，并把 PowerInKW 设为 true
+	Coefficient float64 `json:"coefficient,omitempty"`
+	PowerInKW   bool    `json:"powerInKW,omitempty"` // 按 Coefficient 算出的功率为 kW，能耗以 kWh 报告
+
+	// electrical：各功率列 (kW) 之和
+	PowerColumns []string `json:"powerColumns,omitempty"`
+
+	// diesel：各柴油机按负荷查功率和油耗曲线
+	Engines []DieselEngine `json:"engines,omitempty"`
+
+	Efficiency   float64 `json:"efficiency,omitempty"`   // 效率系数 (0, 1]，功率除以该值，默认 1
+	SpecificFuel float64 `json:"specificFuel,omitempty"` // hydraulic、electrical 模型的单位油耗 L/kWh，为 0 时不计油耗
+}
+
+// EnergyStage 一级泵的进出口压力列
+type EnergyStage struct {
+	Name         string `json:"name"`
+	InletColumn  string `json:"inletColumn"`
+	OutletColumn string `json:"outletColumn"`
+}
+
+// DieselEngine 一台柴油机，按负荷列的读数在曲线上插值得到功率和油耗率
+type DieselEngine struct {
+	Name       string             `json:"name"`
+	LoadColumn string             `json:"loadColumn"` // 如 mud_pump_1_diesel_load
+	Curve      []DieselCurvePoint `json:"curve"`      // 至少两个点，按负荷升序
+}
+
+// DieselCurvePoint 负荷曲线上的一个点
+type DieselCurvePoint struct {
+	Load     float64 `json:"load"`     // 负荷列的读数
+	Power    float64 `json:"power"`    // kW
+	FuelRate float64 `json:"fuelRate"` // L/h
+}
+
+// ShipStateRule 划分运行状态的阈值，为 0 时使用默认值
+type ShipStateRule struct {
+	MinOutputRate  float64 `json:"minOutputRate"`  // 产量率不低于该值且泥泵运转时为挖泥
+	MinPumpSpeed   float64 `json:"minPumpSpeed"`   // 水下泵转速不低于该值时泥泵运转
+	MinCutterSpeed float64 `json:"minCutterSpeed"` // 绞刀转速不低于该值时绞刀运转
+	MinSwingSpeed  float64 `json:"minSwingSpeed"`  // 横移速度绝对值不低于该值时在横移
+	MinSpudStep    float64 `json:"minSpudStep"`    // 相邻记录台车行程变化不低于该值时在移船步进
+}
+
+// StateStat 一个班组在一个周期内各运行状态的时长
+type StateStat struct {
+	ShiftName     string             `json:"shiftName,omitempty"`
+	Crew          string             `json:"crew,omitempty"`
+	Period        string             `json:"period,omitempty"`
+	States        map[string]float64 `json:"states"`        // 运行状态 => 时长（分钟）
+	TotalDuration float64            `json:"totalDuration"` // 各状态的总时长（分钟）
+	Utilization   float64            `json:"utilization"`   // 挖泥时长占总时长的百分比
+}
+
+// Downtime 一段连续的非挖泥状态
+type Downtime struct {
+	State     string  `json:"state"`
+	StateName string  `json:"stateName"`
+	Start     int64   `json:"start"`    // 毫秒
+	End       int64   `json:"end"`      // 毫秒
+	Duration  float64 `json:"duration"` // 分钟
+	ShiftName string  `json:"shiftName"`
+	ShiftDate string  `json:"shiftDate"` // 班次开始的日期
+}
+
+// DataGap 相邻两条记录之间的数据中断
+type DataGap struct {
+	Start    int64   `json:"start"`    // 中断前最后一条记录的时间（毫秒）
+	End      int64   `json:"end"`      // 中断后第一条记录的时间（毫秒）
+	Duration float64 `json:"duration"` // 分钟
+}
+
+// ShiftSchedule 一套班次安排，班次首尾相接地覆盖一整天
+type ShiftSchedule struct {
+	EffectiveFrom string      `json:"effectiveFrom"` // 生效日期 YYYY-MM-DD（船舶时区），为空表示最早的一套
+	Shifts        []ShipShift `json:"shifts"`
+}
+
+// ShipShift 一个班次，Start、End 为船舶时区的 HH:MM；End 不晚于 Start 时班次跨越午夜，归属开始的那一天
+type ShipShift struct {
+	Name  string `json:"name"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+
+	start  int // 开始时间，当天的分钟数
+	length int // 时长（分钟）
+}
+
+// CrewRotation 班组轮换：从 StartDate 起按 Pattern 逐天循环，第 i 天的班次由 Pattern[i] 中的班组值守
+type CrewRotation struct {
+	StartDate string              `json:"startDate"` // YYYY-MM-DD（船舶时区），轮换周期的第一天
+	Pattern   []map[string]string `json:"pattern"`   // 每天的班次名称 => 班组
+}
+
+// CrewAssignment 某天某个班次的值班班组
+type CrewAssignment struct {
+	Date      string `json:"date"`             // 班次开始的日期 YYYY-MM-DD（船舶时区）
+	ShiftName string `json:"shiftName"`        // 班次名称
+	Crew      string `json:"crew"`             // 班组，保存时为空表示删除排班表中的记录
+	Source    string `json:"source,omitempty"` // calendar 来自排班表，rotation 来自轮换，为空表示未排班
+}
+
+// ShipSensorPoints 实时传感器帧中 AI 数据点与数据库列的对应关系
+type ShipSensorPoints struct {
+	Base   int            `json:"base"`   // 帧中第一个 AI 浮点数的点位编号
+	Points map[string]int `json:"points"` // 列名 => 点位编号
+}
+
 type TheoryOptimalParamsDTO struct {
 	ID                           int64     `json:"id"`
 	CreatedAt                    time.Time `json:"createdAt"`
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"dredger/pkg/logger"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultMaxGapSeconds 未配置时，相邻记录间隔超过 5 分钟视为数据中断
+const defaultMaxGapSeconds = 300
+
+// errNegativeThreshold 施工时长和运行状态规则中的阈值为负数
+var errNegativeThreshold = errors.New("阈值不能为负数")
+
+func (r ShipDurationRule) normalize() error {
+	if r.MaxGapSeconds < 0 || r.MinOutputRate < 0 || r.MinPumpSpeed < 0 {
+		return fmt.Errorf("施工时长规则: %w", errNegativeThreshold)
+	}
+	return nil
+}
+
+// maxGap 返回数据中断的阈值（毫秒）
+func (r ShipDurationRule) maxGap() int64 {
+	if r.MaxGapSeconds > 0 {
+		return int64(r.MaxGapSeconds * 1000)
+	}
+	return defaultMaxGapSeconds * 1000
+}
+
+// working 判断从该记录开始的采样间隔是否处于施工状态
+func (r ShipDurationRule) working(rec *record) bool {
+	if r.MinOutputRate > 0 && rec.OutputRate < r.MinOutputRate {
+		return false
+	}
+	if r.MinPumpSpeed > 0 && rec.PumpSpeed < r.MinPumpSpeed {
+		return false
+	}
+	return true
+}
+
+// eachInterval 把记录按班次开始的日期分组（未划分班次时按船舶时区的日期），依次处理组内相邻的两条记录，
+// 跨越两个班次或两天的间隔不处理。records 须按时间升序排列
+func eachInterval(records []*record, loc *time.Location, fn func(prev, cur *record)) {
+	last := make(map[string]*record)
+	for _, r := range records {
+		key := r.ShiftDate
+		if key == "" {
+			key = time.UnixMilli(r.RecordTime).In(loc).Format(time.DateOnly)
+		}
+		if prev := last[key]; prev != nil {
+			fn(prev, r)
+		}
+		last[key] = r
+	}
+}
+
+func newDataGap(start, end int64) DataGap {
+	return DataGap{Start: start, End: end, Duration: round(float64(end-start) / float64(time.Minute/time.Millisecond))}
+}
+
+// GetDataGaps 返回某船 [startTime, endTime] 内相邻记录间隔超过阈值的数据中断，不区分班次
+func (s *Service) GetDataGaps(shipName string, startTime, endTime int64) ([]DataGap, error) {
+	ship, err := lookupShip(shipName)
+	if err != nil {
+		return nil, err
+	}
+	records, err := loadRecords(s.db, ship, startTime, endTime)
+	if err != nil {
+		logger.Logger.Errorf("[%s]查询数据中断失败: %v", shipName, err)
+		return nil, err
+	}
+
+	maxGap := ship.Duration.maxGap()
+	gaps := []DataGap{}
+	for i := 1; i < len(records); i++ {
+		if records[i].RecordTime-records[i-1].RecordTime > maxGap {
+			gaps = append(gaps, newDataGap(records[i-1].RecordTime, records[i].RecordTime))
+		}
+	}
+	return gaps, nil
+}
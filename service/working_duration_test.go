@@ -0,0 +1,110 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMeasureWorkload(t *testing.T) {
+	const minute = int64(time.Minute / time.Millisecond)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC).UnixMilli()
+	// 第 1 到第 11 分钟之间数据中断
+	withGap := []*record{
+		{RecordTime: day, OutputRate: 60, Power: 600},
+		{RecordTime: day + minute, OutputRate: 120, Power: 1200},
+		{RecordTime: day + 11*minute, OutputRate: 180},
+		{RecordTime: day + 12*minute, OutputRate: 60},
+	}
+
+	tests := []struct {
+		name           string
+		records        []*record
+		rule           ShipDurationRule
+		wantDuration   float64
+		wantProduction float64
+		wantEnergy     float64
+		wantGaps       []DataGap
+	}{
+		{
+			name:           "数据中断不计入时长",
+			records:        withGap,
+			wantDuration:   2,
+			wantProduction: 1.5 + 2,
+			wantEnergy:     15,
+			wantGaps:       []DataGap{{Start: day + minute, End: day + 11*minute, Duration: 10}},
+		},
+		{
+			name:           "中断阈值调大后计入",
+			records:        withGap,
+			rule:           ShipDurationRule{MaxGapSeconds: 900},
+			wantDuration:   12,
+			wantProduction: 1.5 + 25 + 2,
+			wantEnergy:     15 + 100,
+		},
+		{
+			name:           "间隔起点产量率不足的不计入",
+			records:        withGap,
+			rule:           ShipDurationRule{MinOutputRate: 100},
+			wantDuration:   1,
+			wantProduction: 2,
+			wantGaps:       []DataGap{{Start: day + minute, End: day + 11*minute, Duration: 10}},
+		},
+		{
+			name: "跨越两天的间隔不处理",
+			records: []*record{
+				{RecordTime: day - minute, OutputRate: 60},
+				{RecordTime: day + minute, OutputRate: 60},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := measureWorkload(tt.records, time.UTC, tt.rule)
+			if math.Abs(w.Duration-tt.wantDuration) > 1e-9 {
+				t.Errorf("Duration = %v, want %v", w.Duration, tt.wantDuration)
+			}
+			if math.Abs(w.Production-tt.wantProduction) > 1e-9 {
+				t.Errorf("Production = %v, want %v", w.Production, tt.wantProduction)
+			}
+			if math.Abs(w.Energy-tt.wantEnergy) > 1e-9 {
+				t.Errorf("Energy = %v, want %v", w.Energy, tt.wantEnergy)
+			}
+			if !reflect.DeepEqual(w.Gaps, tt.wantGaps) {
+				t.Errorf("Gaps = %+v, want %+v", w.Gaps, tt.wantGaps)
+			}
+		})
+	}
+}
+
+func TestMeasureWorkloadEstimatedProduction(t *testing.T) {
+	const minute = int64(time.Minute / time.Millisecond)
+	day := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC).UnixMilli()
+	// 流量 × 浓度估算的产量率依次为 60、120、180 m³/h，与产量率计的读数无关
+	records := []*record{
+		{RecordTime: day, FlowRate: 600, Concentration: 10, OutputRate: 100},
+		{RecordTime: day + minute, FlowRate: 800, Concentration: 15, OutputRate: 50},
+		{RecordTime: day + 2*minute, FlowRate: 900, Concentration: 20},
+	}
+
+	tests := []struct {
+		name           string
+		rule           ShipDurationRule
+		wantProduction float64
+		wantEstimated  float64
+	}{
+		{name: "按梯形法积分", wantProduction: 1.25 + 25.0/60, wantEstimated: 1.5 + 2.5},
+		{name: "间隔起点未施工的不计入", rule: ShipDurationRule{MinOutputRate: 80}, wantProduction: 1.25, wantEstimated: 1.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := measureWorkload(records, time.UTC, tt.rule)
+			if math.Abs(w.Production-tt.wantProduction) > 1e-9 || math.Abs(w.EstimatedProduction-tt.wantEstimated) > 1e-9 {
+				t.Errorf("Production, EstimatedProduction = %v, %v, want %v, %v", w.Production, w.EstimatedProduction, tt.wantProduction, tt.wantEstimated)
+			}
+		})
+	}
+}